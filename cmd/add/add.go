@@ -15,6 +15,7 @@ import (
 	"path"
 	"strings"
 
+	"github.com/NicksPatties/sweet/config"
 	"github.com/NicksPatties/sweet/util"
 	"github.com/spf13/cobra"
 )
@@ -46,15 +47,11 @@ func addExercise(cmd *cobra.Command, args []string) (err error) {
 	}
 	defer inputFile.Close()
 
-	// Create the new exercise file in the configuration directory.
-	sweetConfigDir, err := util.SweetConfigDir()
+	// Create the new exercise file in the exercises directory.
+	exercisesDir, err := config.ExercisesDir()
 	if err != nil {
 		return
 	}
-	exercisesDir := path.Join(sweetConfigDir, "exercises")
-	if envDir := os.Getenv("SWEET_EXERCISES_DIR"); envDir != "" {
-		exercisesDir = envDir
-	}
 	newExercisePath := path.Join(exercisesDir, path.Base(pathName))
 	// if a file exists at newExercisePath, then I should error
 	newExerciseFile, err := os.OpenFile(newExercisePath, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0666)
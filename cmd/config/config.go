@@ -0,0 +1,89 @@
+/*
+config - Gets and sets the values of sweet's configuration file.
+
+Usage:
+
+	sweet config list
+	sweet config get key
+	sweet config set key value
+*/
+package config
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/NicksPatties/sweet/config"
+	"github.com/spf13/cobra"
+)
+
+var Cmd = &cobra.Command{
+	Use:   "config",
+	Short: "Get and set configuration values",
+	Long: "Get and set the default values of sweet's flags, which are saved in the config file.\n" +
+		"Flags and environment variables take precedence over the config file.",
+}
+
+var listCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List every config key and its value",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		conf, err := config.Load()
+		if err != nil {
+			return err
+		}
+		return list(cmd.OutOrStdout(), conf)
+	},
+}
+
+var getCmd = &cobra.Command{
+	Use:   "get key",
+	Short: "Print the value of a config key",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		conf, err := config.Load()
+		if err != nil {
+			return err
+		}
+		value, err := conf.Get(args[0])
+		if err != nil {
+			return err
+		}
+		fmt.Fprintln(cmd.OutOrStdout(), value)
+		return nil
+	},
+}
+
+var setCmd = &cobra.Command{
+	Use:   "set key value",
+	Short: "Set the value of a config key. An empty value unsets it",
+	Args:  cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		conf, err := config.Load()
+		if err != nil {
+			return err
+		}
+		if err := conf.Set(args[0], args[1]); err != nil {
+			return err
+		}
+		return config.Save(conf)
+	},
+}
+
+// Prints every key of the config, and its value. Keys
+// that aren't set have no value.
+func list(w io.Writer, conf config.Config) error {
+	for _, key := range conf.Keys() {
+		value, err := conf.Get(key)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(w, "%s=%s\n", key, value)
+	}
+	return nil
+}
+
+func init() {
+	Cmd.AddCommand(listCmd, getCmd, setCmd)
+}
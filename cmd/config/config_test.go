@@ -0,0 +1,48 @@
+package config
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/NicksPatties/sweet/config"
+)
+
+func TestSetAndGet(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+
+	run := func(args ...string) (string, error) {
+		out := &bytes.Buffer{}
+		Cmd.SetOut(out)
+		Cmd.SetArgs(args)
+		err := Cmd.Execute()
+		return out.String(), err
+	}
+
+	if _, err := run("set", "layout", "workman"); err != nil {
+		t.Fatalf("failed to set layout: %v", err)
+	}
+	got, err := run("get", "layout")
+	if err != nil {
+		t.Fatalf("failed to get layout: %v", err)
+	}
+	if got != "workman\n" {
+		t.Errorf("got %q, want %q", got, "workman\n")
+	}
+
+	if _, err := run("set", "layout", "azerty"); err == nil {
+		t.Errorf("setting an unknown layout should error")
+	}
+
+	got, err = run("list")
+	if err != nil {
+		t.Fatalf("failed to list config: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(got), "\n")
+	if want := len(config.Config{}.Keys()); len(lines) != want {
+		t.Errorf("got %d lines, want one for each key (%d)", len(lines), want)
+	}
+	if !strings.Contains(got, "layout=workman\n") || !strings.Contains(got, "window-size=\n") {
+		t.Errorf("list is missing values:\n%s", got)
+	}
+}
@@ -0,0 +1,284 @@
+/*
+export - Writes reps to a file, so they can be analyzed with other tools.
+
+Usage:
+
+	sweet export [flags]
+*/
+package export
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/NicksPatties/sweet/cmd/stats"
+	c "github.com/NicksPatties/sweet/constants"
+	"github.com/NicksPatties/sweet/db"
+	"github.com/spf13/cobra"
+)
+
+// The formats reps can be exported to.
+const (
+	CSV    = "csv"
+	JSON   = "json"
+	NDJSON = "ndjson"
+)
+
+var Formats = []string{CSV, JSON, NDJSON}
+
+var Cmd = &cobra.Command{
+	Use:   "export",
+	Short: "Export reps as CSV, JSON, or NDJSON",
+	Long: "Export reps as CSV, JSON, or NDJSON.\n" +
+		"Reps are chosen with the same flags as the stats command.",
+	Args: cobra.NoArgs,
+	Example: "  export today's reps as CSV\n" +
+		"  sweet export\n\n" +
+		"  export the past month of Go reps as JSON\n" +
+		"  sweet export --since=1m --lang=go --format=json\n\n" +
+		"  export every rep and its events to a file\n" +
+		"  sweet export --since=100y --events -o reps.ndjson",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		format, err := formatFromArgs(cmd)
+		if err != nil {
+			return err
+		}
+		withEvents, err := cmd.Flags().GetBool(c.EVENTS)
+		if err != nil {
+			return err
+		}
+		reps, err := stats.FilteredReps(cmd, time.Now())
+		if err != nil {
+			return err
+		}
+		if withEvents {
+			statsDb, err := db.SweetDb()
+			if err != nil {
+				return fmt.Errorf("failed to connect to database: %s", err)
+			}
+			defer statsDb.Close()
+			if err := db.LoadEvents(statsDb, reps); err != nil {
+				return fmt.Errorf("failed to get events: %s", err)
+			}
+		}
+
+		out := cmd.OutOrStdout()
+		if output, _ := cmd.Flags().GetString("output"); output != "" {
+			file, err := os.Create(output)
+			if err != nil {
+				return err
+			}
+			defer file.Close()
+			out = file
+		}
+
+		return Write(out, format, reps, withEvents)
+	},
+}
+
+// Returns the format flag. If the format isn't given, then it's
+// taken from the output file's extension, or CSV if that doesn't
+// match a format.
+func formatFromArgs(cmd *cobra.Command) (string, error) {
+	format, err := cmd.Flags().GetString("format")
+	if err != nil {
+		return "", err
+	}
+	output, err := cmd.Flags().GetString("output")
+	if err != nil {
+		return "", err
+	}
+	if !cmd.Flags().Changed("format") {
+		ext := strings.TrimPrefix(filepath.Ext(output), ".")
+		for _, f := range Formats {
+			if strings.EqualFold(ext, f) {
+				return f, nil
+			}
+		}
+	}
+	for _, f := range Formats {
+		if strings.EqualFold(format, f) {
+			return f, nil
+		}
+	}
+	return "", fmt.Errorf("unknown format %s (use one of %s)", format, strings.Join(Formats, ", "))
+}
+
+// A rep as it's written in JSON and NDJSON exports.
+// The duration and time limit are in nanoseconds,
+// the same as they are in the database.
+type Record struct {
+	Id     int       `json:"id"`
+	Hash   string    `json:"hash"`
+	Start  time.Time `json:"start"`
+	End    time.Time `json:"end"`
+	Name   string    `json:"name"`
+	Lang   string    `json:"lang"`
+	Wpm    float64   `json:"wpm"`
+	Raw    float64   `json:"raw"`
+	Dur    int64     `json:"dur"`
+	Acc    float64   `json:"acc"`
+	Miss   int       `json:"miss"`
+	Errs   int       `json:"errs"`
+	Lim    int64     `json:"lim"`
+	Strict bool      `json:"strict"`
+	Pauses []Pause   `json:"pauses"`
+	Events []Event   `json:"events,omitempty"`
+}
+
+type Pause struct {
+	Start time.Time `json:"start"`
+	End   time.Time `json:"end"`
+}
+
+type Event struct {
+	Ts       time.Time `json:"ts"`
+	I        int       `json:"i"`
+	Typed    string    `json:"typed"`
+	Expected string    `json:"expected"`
+}
+
+// Converts a rep to a record. The events are left
+// out unless withEvents is true.
+func ToRecord(rep db.Rep, withEvents bool) Record {
+	r := Record{
+		Id:     rep.Id,
+		Hash:   rep.Hash,
+		Start:  rep.Start,
+		End:    rep.End,
+		Name:   rep.Name,
+		Lang:   rep.Lang,
+		Wpm:    rep.Wpm,
+		Raw:    rep.Raw,
+		Dur:    int64(rep.Dur),
+		Acc:    rep.Acc,
+		Miss:   rep.Miss,
+		Errs:   rep.Errs,
+		Lim:    int64(rep.Lim),
+		Strict: rep.Strict,
+		Pauses: []Pause{},
+	}
+	for _, p := range rep.Pauses {
+		r.Pauses = append(r.Pauses, Pause{Start: p.Start, End: p.End})
+	}
+	if withEvents {
+		for _, e := range rep.Events {
+			r.Events = append(r.Events, Event{Ts: e.Ts, I: e.I, Typed: e.Typed, Expected: e.Expected})
+		}
+	}
+	return r
+}
+
+// The columns of a CSV export. The events column
+// is only added if the events are exported.
+var csvColumns = []string{
+	c.ID, c.HASH, c.START, c.END, c.NAME, c.LANGUAGE, c.WPM, c.RAW_WPM,
+	c.DURATION, c.ACCURACY, c.MISTAKES, c.UNCORRECTED_ERRORS,
+	c.TIME_LIMIT, c.STRICT, c.PAUSES,
+}
+
+// Converts a column of a rep to a CSV field. Times are in
+// RFC 3339 format, and durations are in nanoseconds. Pauses and
+// events are written the same way they're saved in the database.
+func csvField(rep db.Rep, col string) string {
+	switch col {
+	case c.START:
+		return rep.Start.Format(time.RFC3339Nano)
+	case c.END:
+		return rep.End.Format(time.RFC3339Nano)
+	case c.WPM:
+		return strconv.FormatFloat(rep.Wpm, 'f', -1, 64)
+	case c.RAW_WPM:
+		return strconv.FormatFloat(rep.Raw, 'f', -1, 64)
+	case c.DURATION:
+		return strconv.FormatInt(int64(rep.Dur), 10)
+	case c.ACCURACY:
+		return strconv.FormatFloat(rep.Acc, 'f', -1, 64)
+	case c.TIME_LIMIT:
+		return strconv.FormatInt(int64(rep.Lim), 10)
+	case c.EVENTS:
+		events := []string{}
+		for _, e := range rep.Events {
+			events = append(events, e.String())
+		}
+		return strings.Join(events, "\n")
+	default:
+		return rep.ColumnString(col)
+	}
+}
+
+func writeCSV(w io.Writer, reps []db.Rep, withEvents bool) error {
+	cols := csvColumns
+	if withEvents {
+		cols = append(cols[:len(cols):len(cols)], c.EVENTS)
+	}
+	cw := csv.NewWriter(w)
+	if err := cw.Write(cols); err != nil {
+		return err
+	}
+	for _, rep := range reps {
+		row := []string{}
+		for _, col := range cols {
+			row = append(row, csvField(rep, col))
+		}
+		if err := cw.Write(row); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+func writeJSON(w io.Writer, reps []db.Rep, withEvents bool) error {
+	records := []Record{}
+	for _, rep := range reps {
+		records = append(records, ToRecord(rep, withEvents))
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(records)
+}
+
+func writeNDJSON(w io.Writer, reps []db.Rep, withEvents bool) error {
+	enc := json.NewEncoder(w)
+	for _, rep := range reps {
+		if err := enc.Encode(ToRecord(rep, withEvents)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Writes reps to w in the given format. The events
+// of each rep are only written if withEvents is true.
+func Write(w io.Writer, format string, reps []db.Rep, withEvents bool) error {
+	switch format {
+	case CSV:
+		return writeCSV(w, reps, withEvents)
+	case JSON:
+		return writeJSON(w, reps, withEvents)
+	case NDJSON:
+		return writeNDJSON(w, reps, withEvents)
+	default:
+		return fmt.Errorf("unknown format %s", format)
+	}
+}
+
+func setExportCommandFlags(cmd *cobra.Command) {
+	stats.SetFilterFlags(cmd)
+	cmd.Flags().StringP("format", "f", CSV, fmt.Sprintf("output format (%s)", strings.Join(Formats, ", ")))
+	cmd.Flags().StringP("output", "o", "", "write to this file instead of stdout")
+	cmd.Flags().Bool(c.EVENTS, false, "include each rep's keystroke events")
+	cmd.Flags().SortFlags = false
+}
+
+func init() {
+	setExportCommandFlags(Cmd)
+}
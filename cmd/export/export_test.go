@@ -0,0 +1,177 @@
+package export
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/NicksPatties/sweet/db"
+	"github.com/NicksPatties/sweet/event"
+	"github.com/spf13/cobra"
+)
+
+func mockReps() []db.Rep {
+	start := time.Date(2024, time.December, 6, 17, 36, 20, 0, time.UTC)
+	return []db.Rep{
+		{
+			Id:    1,
+			Hash:  "abc",
+			Start: start,
+			End:   start.Add(2 * time.Second),
+			Name:  "hello.go",
+			Lang:  "go",
+			Wpm:   60.5,
+			Raw:   62,
+			Dur:   2 * time.Second,
+			Acc:   97.5,
+			Miss:  1,
+			Errs:  0,
+			Events: event.Events{
+				{Ts: start, I: 0, Typed: "h", Expected: "h"},
+				{Ts: start.Add(time.Second), I: 1, Typed: "i", Expected: "i"},
+			},
+			Pauses: event.Pauses{
+				{Start: start.Add(time.Second), End: start.Add(1500 * time.Millisecond)},
+			},
+		},
+		{
+			Id:     2,
+			Hash:   "def",
+			Start:  start.Add(time.Minute),
+			End:    start.Add(2 * time.Minute),
+			Name:   "quote, \"with\" commas.txt",
+			Lang:   "txt",
+			Dur:    time.Minute,
+			Lim:    time.Minute,
+			Strict: true,
+		},
+	}
+}
+
+func TestWriteCSV(t *testing.T) {
+	for _, withEvents := range []bool{false, true} {
+		var buf bytes.Buffer
+		if err := Write(&buf, CSV, mockReps(), withEvents); err != nil {
+			t.Fatalf("failed to write csv: %v", err)
+		}
+		rows, err := csv.NewReader(&buf).ReadAll()
+		if err != nil {
+			t.Fatalf("failed to read csv: %v", err)
+		}
+		if len(rows) != 3 {
+			t.Fatalf("got %d rows, want a header and two reps", len(rows))
+		}
+		header := strings.Join(rows[0], ",")
+		if withEvents != strings.HasSuffix(header, ",events") {
+			t.Errorf("with events %t got header %s", withEvents, header)
+		}
+		want := []string{"1", "abc", "2024-12-06T17:36:20Z", "2024-12-06T17:36:22Z", "hello.go", "go", "60.5", "62", "2000000000", "97.5", "1", "0", "0", "false"}
+		for i, w := range want {
+			if rows[1][i] != w {
+				t.Errorf("%s column got %q, want %q", rows[0][i], rows[1][i], w)
+			}
+		}
+		if name := rows[2][4]; name != mockReps()[1].Name {
+			t.Errorf("got name %q, want %q", name, mockReps()[1].Name)
+		}
+		if withEvents {
+			if events := event.ParseEvents(rows[1][15]); len(events) != 2 || !events[1].Matches(mockReps()[0].Events[1]) {
+				t.Errorf("events weren't written like the database column, got %q", rows[1][15])
+			}
+		}
+	}
+}
+
+func TestWriteJSON(t *testing.T) {
+	var buf bytes.Buffer
+	if err := Write(&buf, JSON, mockReps(), false); err != nil {
+		t.Fatalf("failed to write json: %v", err)
+	}
+	var records []Record
+	if err := json.Unmarshal(buf.Bytes(), &records); err != nil {
+		t.Fatalf("failed to read json: %v", err)
+	}
+	if len(records) != 2 {
+		t.Fatalf("got %d records, want 2", len(records))
+	}
+	got := records[0]
+	if got.Name != "hello.go" || got.Dur != int64(2*time.Second) || !got.Start.Equal(mockReps()[0].Start) {
+		t.Errorf("got record %+v", got)
+	}
+	if len(got.Pauses) != 1 {
+		t.Errorf("got %d pauses, want 1", len(got.Pauses))
+	}
+	if got.Events != nil {
+		t.Errorf("events should be left out, got %v", got.Events)
+	}
+
+	buf.Reset()
+	if err := Write(&buf, JSON, nil, false); err != nil {
+		t.Fatalf("failed to write json: %v", err)
+	}
+	if got := strings.TrimSpace(buf.String()); got != "[]" {
+		t.Errorf("no reps should be an empty array, got %s", got)
+	}
+}
+
+func TestWriteNDJSON(t *testing.T) {
+	var buf bytes.Buffer
+	if err := Write(&buf, NDJSON, mockReps(), true); err != nil {
+		t.Fatalf("failed to write ndjson: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("got %d lines, want 2", len(lines))
+	}
+	var got Record
+	if err := json.Unmarshal([]byte(lines[0]), &got); err != nil {
+		t.Fatalf("failed to read line: %v", err)
+	}
+	if len(got.Events) != 2 || got.Events[1].Typed != "i" {
+		t.Errorf("got events %v", got.Events)
+	}
+	var empty Record
+	if err := json.Unmarshal([]byte(lines[1]), &empty); err != nil {
+		t.Fatalf("failed to read line: %v", err)
+	}
+	if len(empty.Events) != 0 || !empty.Strict {
+		t.Errorf("got record %+v", empty)
+	}
+}
+
+func TestFormatFromArgs(t *testing.T) {
+	testCases := []struct {
+		args    []string
+		want    string
+		wantErr bool
+	}{
+		{args: []string{}, want: CSV},
+		{args: []string{"--format", "JSON"}, want: JSON},
+		{args: []string{"-o", "reps.ndjson"}, want: NDJSON},
+		{args: []string{"-o", "reps.txt"}, want: CSV},
+		{args: []string{"-o", "reps.ndjson", "-f", "csv"}, want: CSV},
+		{args: []string{"-f", "xml"}, wantErr: true},
+	}
+
+	for _, tc := range testCases {
+		cmd := &cobra.Command{
+			Run: func(cmd *cobra.Command, args []string) {
+				got, err := formatFromArgs(cmd)
+				if tc.wantErr && err == nil {
+					t.Errorf("%v wanted error, got nil", tc.args)
+				}
+				if got != tc.want {
+					t.Errorf("%v got %q, want %q", tc.args, got, tc.want)
+				}
+			},
+		}
+		setExportCommandFlags(cmd)
+		cmd.SetArgs(tc.args)
+		if err := cmd.Execute(); err != nil {
+			t.Fatalf("mock command failed to run: %s", err)
+		}
+	}
+}
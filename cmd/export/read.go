@@ -0,0 +1,180 @@
+package export
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	c "github.com/NicksPatties/sweet/constants"
+	"github.com/NicksPatties/sweet/db"
+	"github.com/NicksPatties/sweet/event"
+)
+
+// Converts a record back to a rep.
+func (r Record) Rep() db.Rep {
+	rep := db.Rep{
+		Id:     r.Id,
+		Hash:   r.Hash,
+		Start:  r.Start,
+		End:    r.End,
+		Name:   r.Name,
+		Lang:   r.Lang,
+		Wpm:    r.Wpm,
+		Raw:    r.Raw,
+		Dur:    time.Duration(r.Dur),
+		Acc:    r.Acc,
+		Miss:   r.Miss,
+		Errs:   r.Errs,
+		Lim:    time.Duration(r.Lim),
+		Strict: r.Strict,
+	}
+	for _, p := range r.Pauses {
+		rep.Pauses = append(rep.Pauses, event.Pause{Start: p.Start, End: p.End})
+	}
+	for _, e := range r.Events {
+		rep.Events = append(rep.Events, event.Event{Ts: e.Ts, I: e.I, Typed: e.Typed, Expected: e.Expected})
+	}
+	return rep
+}
+
+// Sets a column of a rep from a CSV field. This is
+// the opposite of `csvField`.
+func setCSVField(rep *db.Rep, col string, field string) (err error) {
+	switch col {
+	case c.ID:
+		rep.Id, err = strconv.Atoi(field)
+	case c.HASH:
+		rep.Hash = field
+	case c.START:
+		rep.Start, err = time.Parse(time.RFC3339Nano, field)
+	case c.END:
+		rep.End, err = time.Parse(time.RFC3339Nano, field)
+	case c.NAME:
+		rep.Name = field
+	case c.LANGUAGE:
+		rep.Lang = field
+	case c.WPM:
+		rep.Wpm, err = strconv.ParseFloat(field, 64)
+	case c.RAW_WPM:
+		rep.Raw, err = strconv.ParseFloat(field, 64)
+	case c.DURATION:
+		var dur int64
+		dur, err = strconv.ParseInt(field, 10, 64)
+		rep.Dur = time.Duration(dur)
+	case c.ACCURACY:
+		rep.Acc, err = strconv.ParseFloat(field, 64)
+	case c.MISTAKES:
+		rep.Miss, err = strconv.Atoi(field)
+	case c.UNCORRECTED_ERRORS:
+		rep.Errs, err = strconv.Atoi(field)
+	case c.TIME_LIMIT:
+		var lim int64
+		lim, err = strconv.ParseInt(field, 10, 64)
+		rep.Lim = time.Duration(lim)
+	case c.STRICT:
+		rep.Strict, err = strconv.ParseBool(field)
+	case c.PAUSES:
+		rep.Pauses = event.ParsePauses(field)
+	case c.EVENTS:
+		rep.Events = event.ParseEvents(field)
+	}
+	if err != nil {
+		return fmt.Errorf("invalid %s %q: %v", col, field, err)
+	}
+	return nil
+}
+
+func readCSV(r io.Reader) (reps []db.Rep, err error) {
+	cr := csv.NewReader(r)
+	header, err := cr.Read()
+	if err == io.EOF {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	for {
+		row, err := cr.Read()
+		if err == io.EOF {
+			return reps, nil
+		}
+		if err != nil {
+			return reps, err
+		}
+		var rep db.Rep
+		for i, col := range header {
+			if err := setCSVField(&rep, col, row[i]); err != nil {
+				line, _ := cr.FieldPos(i)
+				return reps, fmt.Errorf("line %d: %v", line, err)
+			}
+		}
+		reps = append(reps, rep)
+	}
+}
+
+func readJSON(r io.Reader) (reps []db.Rep, err error) {
+	var records []Record
+	if err := json.NewDecoder(r).Decode(&records); err != nil {
+		return nil, err
+	}
+	for _, record := range records {
+		reps = append(reps, record.Rep())
+	}
+	return reps, nil
+}
+
+func readNDJSON(r io.Reader) (reps []db.Rep, err error) {
+	dec := json.NewDecoder(r)
+	for {
+		var record Record
+		err := dec.Decode(&record)
+		if err == io.EOF {
+			return reps, nil
+		}
+		if err != nil {
+			return reps, err
+		}
+		reps = append(reps, record.Rep())
+	}
+}
+
+// Reads reps that were written by `Write` in the given format.
+func Read(r io.Reader, format string) ([]db.Rep, error) {
+	switch format {
+	case CSV:
+		return readCSV(r)
+	case JSON:
+		return readJSON(r)
+	case NDJSON:
+		return readNDJSON(r)
+	default:
+		return nil, fmt.Errorf("unknown format %s", format)
+	}
+}
+
+// Guesses the format of an export from its first bytes. JSON
+// starts with an array, NDJSON with an object, and anything
+// else is assumed to be CSV.
+func DetectFormat(r *bufio.Reader) string {
+	for {
+		b, err := r.Peek(1)
+		if err != nil {
+			return CSV
+		}
+		switch {
+		case b[0] == '[':
+			return JSON
+		case b[0] == '{':
+			return NDJSON
+		case strings.ContainsRune(" \t\r\n", rune(b[0])):
+			r.ReadByte()
+		default:
+			return CSV
+		}
+	}
+}
@@ -0,0 +1,47 @@
+package export
+
+import (
+	"bufio"
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestRead(t *testing.T) {
+	want := mockReps()
+	for _, format := range Formats {
+		var buf bytes.Buffer
+		if err := Write(&buf, format, want, true); err != nil {
+			t.Fatalf("%s: failed to write: %v", format, err)
+		}
+		r := bufio.NewReader(&buf)
+		if got := DetectFormat(r); got != format {
+			t.Errorf("%s: detected format %s", format, got)
+		}
+		got, err := Read(r, format)
+		if err != nil {
+			t.Fatalf("%s: failed to read: %v", format, err)
+		}
+		if len(got) != len(want) {
+			t.Fatalf("%s: got %d reps, want %d", format, len(got), len(want))
+		}
+		for i := range want {
+			g, w := got[i], want[i]
+			if g.Hash != w.Hash || !g.Start.Equal(w.Start) || !g.End.Equal(w.End) ||
+				g.Name != w.Name || g.Wpm != w.Wpm || g.Dur != w.Dur || g.Lim != w.Lim || g.Strict != w.Strict {
+				t.Errorf("%s:\n got  %v\n want %v", format, g, w)
+			}
+			if len(g.Events) != len(w.Events) || len(g.Pauses) != len(w.Pauses) {
+				t.Errorf("%s: got %d events and %d pauses, want %d and %d",
+					format, len(g.Events), len(g.Pauses), len(w.Events), len(w.Pauses))
+			}
+		}
+	}
+}
+
+func TestReadCSV_invalid(t *testing.T) {
+	in := "hash,start\nabc,yesterday\n"
+	if _, err := Read(strings.NewReader(in), CSV); err == nil {
+		t.Errorf("wanted error for an invalid start time, got nil")
+	}
+}
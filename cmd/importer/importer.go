@@ -0,0 +1,151 @@
+/*
+import - Adds reps from exports or another sweet database to your stats.
+
+Usage:
+
+	sweet import file...
+*/
+package importer
+
+import (
+	"bufio"
+	"bytes"
+	"database/sql"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/NicksPatties/sweet/cmd/export"
+	"github.com/NicksPatties/sweet/db"
+	"github.com/spf13/cobra"
+)
+
+var Cmd = &cobra.Command{
+	Use:   "import file...",
+	Short: "Import reps from exports or another sweet database",
+	Long: "Import reps from files made by the export command, or from another sweet.db file.\n" +
+		"Reps of the same exercise that started and ended at the same times are only imported once.",
+	Args: cobra.MinimumNArgs(1),
+	Example: "  merge the reps from another computer\n" +
+		"  sweet import ~/laptop/sweet.db\n\n" +
+		"  import reps from an export\n" +
+		"  sweet import reps.csv",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		statsDb, err := db.SweetDb()
+		if err != nil {
+			return fmt.Errorf("failed to connect to database: %s", err)
+		}
+		defer statsDb.Close()
+
+		for _, file := range args {
+			result, err := importFile(statsDb, file)
+			if err != nil {
+				return fmt.Errorf("failed to import %s: %v", file, err)
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "%s: %s\n", file, result)
+		}
+		return nil
+	},
+}
+
+// The number of reps and exercises added from a file.
+type result struct {
+	added     int
+	skipped   int
+	exercises int
+}
+
+func (r result) String() string {
+	s := fmt.Sprintf("added %d reps, skipped %d duplicates", r.added, r.skipped)
+	if r.exercises > 0 {
+		s += fmt.Sprintf(", added %d exercises", r.exercises)
+	}
+	return s
+}
+
+// The first bytes of every SQLite database file.
+var sqliteHeader = []byte("SQLite format 3\x00")
+
+// Imports the reps of a file into the database. The file is
+// either an export or a sweet database, depending on its contents.
+func importFile(statsDb *sql.DB, file string) (res result, err error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return
+	}
+	defer f.Close()
+
+	r := bufio.NewReader(f)
+	header, err := r.Peek(len(sqliteHeader))
+	if err != nil && err != io.EOF {
+		return
+	}
+	if bytes.Equal(header, sqliteHeader) {
+		f.Close()
+		return importDb(statsDb, file)
+	}
+
+	reps, err := export.Read(r, export.DetectFormat(r))
+	if err != nil {
+		return
+	}
+	return importReps(statsDb, reps)
+}
+
+// Imports the reps and exercises of another sweet database.
+// The other database is only read, so it isn't migrated, and
+// can be from an older or newer version of sweet.
+func importDb(statsDb *sql.DB, file string) (res result, err error) {
+	otherDb, err := db.OpenReadOnly(file)
+	if err != nil {
+		return
+	}
+	defer otherDb.Close()
+
+	reps, err := db.ReadReps(otherDb)
+	if err != nil {
+		return
+	}
+	res, err = importReps(statsDb, reps)
+	if err != nil {
+		return
+	}
+
+	exercises, err := db.ReadExercises(otherDb)
+	if err != nil {
+		return
+	}
+	for _, ex := range exercises {
+		text, err := db.GetExerciseText(statsDb, ex.Hash)
+		if err != nil {
+			return res, err
+		}
+		if text != "" {
+			continue
+		}
+		if err := db.InsertExercise(statsDb, ex.Hash, ex.Name, ex.Text); err != nil {
+			return res, err
+		}
+		res.exercises++
+	}
+	return
+}
+
+// Inserts the reps that aren't in the database already.
+func importReps(statsDb *sql.DB, reps []db.Rep) (res result, err error) {
+	for _, rep := range reps {
+		found, err := db.HasRep(statsDb, rep.Hash, rep.Start, rep.End)
+		if err != nil {
+			return res, err
+		}
+		if found {
+			res.skipped++
+			continue
+		}
+		if _, err := db.InsertRep(statsDb, rep); err != nil {
+			return res, err
+		}
+		res.added++
+	}
+	return
+}
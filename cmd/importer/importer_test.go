@@ -0,0 +1,147 @@
+package importer
+
+import (
+	"bytes"
+	"os"
+	"path"
+	"testing"
+	"time"
+
+	"github.com/NicksPatties/sweet/cmd/export"
+	"github.com/NicksPatties/sweet/db"
+	"github.com/NicksPatties/sweet/event"
+)
+
+func mockRep(hash string, start time.Time) db.Rep {
+	return db.Rep{
+		Hash:  hash,
+		Start: start,
+		End:   start.Add(10 * time.Second),
+		Name:  hash + ".txt",
+		Lang:  "txt",
+		Dur:   10 * time.Second,
+	}
+}
+
+func TestImportFile(t *testing.T) {
+	t.Setenv("SWEET_DB_LOCATION", t.TempDir())
+	statsDb, err := db.SweetDb()
+	if err != nil {
+		t.Fatalf("failed to open database: %v", err)
+	}
+	defer statsDb.Close()
+
+	start := time.Date(2024, 10, 7, 13, 46, 47, 0, time.UTC)
+	if _, err := db.InsertRep(statsDb, mockRep("abc", start)); err != nil {
+		t.Fatalf("failed to insert rep: %v", err)
+	}
+
+	t.Run("export file", func(t *testing.T) {
+		file := path.Join(t.TempDir(), "reps.csv")
+		f, err := os.Create(file)
+		if err != nil {
+			t.Fatalf("failed to create export: %v", err)
+		}
+		reps := []db.Rep{mockRep("abc", start), mockRep("def", start)}
+		if err := export.Write(f, export.CSV, reps, false); err != nil {
+			t.Fatalf("failed to write export: %v", err)
+		}
+		f.Close()
+
+		got, err := importFile(statsDb, file)
+		if err != nil {
+			t.Fatalf("failed to import: %v", err)
+		}
+		if want := (result{added: 1, skipped: 1}); got != want {
+			t.Errorf("got %v, want %v", got, want)
+		}
+	})
+
+	t.Run("sweet database", func(t *testing.T) {
+		file := path.Join(t.TempDir(), "sweet.db")
+		otherDb, err := db.Open(file)
+		if err != nil {
+			t.Fatalf("failed to create other database: %v", err)
+		}
+		withEvents := mockRep("ghi", start)
+		withEvents.Events = event.Events{
+			{Ts: start, I: 0, Typed: "g", Expected: "g"},
+			{Ts: start.Add(time.Second), I: 1, Typed: "h", Expected: "h"},
+		}
+		for _, rep := range []db.Rep{mockRep("def", start), withEvents} {
+			if _, err := db.InsertRep(otherDb, rep); err != nil {
+				t.Fatalf("failed to insert rep: %v", err)
+			}
+		}
+		if err := db.InsertExercise(otherDb, "ghi", "ghi.txt", "ghi\n"); err != nil {
+			t.Fatalf("failed to insert exercise: %v", err)
+		}
+		otherDb.Close()
+
+		got, err := importFile(statsDb, file)
+		if err != nil {
+			t.Fatalf("failed to import: %v", err)
+		}
+		if want := (result{added: 1, skipped: 1, exercises: 1}); got != want {
+			t.Errorf("got %v, want %v", got, want)
+		}
+		if text, _ := db.GetExerciseText(statsDb, "ghi"); text != "ghi\n" {
+			t.Errorf("exercise text wasn't imported, got %q", text)
+		}
+		imported, err := db.GetFastestRep(statsDb, "ghi")
+		if err != nil || imported == nil {
+			t.Fatalf("failed to get imported rep: %v", err)
+		}
+		if len(imported.Events) != 2 {
+			t.Errorf("got %d imported events, want 2", len(imported.Events))
+		}
+
+		got, err = importFile(statsDb, file)
+		if err != nil {
+			t.Fatalf("failed to import again: %v", err)
+		}
+		if want := (result{skipped: 2}); got != want {
+			t.Errorf("importing again got %v, want %v", got, want)
+		}
+	})
+
+	t.Run("old sweet database isn't changed", func(t *testing.T) {
+		data, err := os.ReadFile(path.Join("..", "..", "db", "testdata", "pauses.db"))
+		if err != nil {
+			t.Fatalf("failed to read fixture: %v", err)
+		}
+		file := path.Join(t.TempDir(), "sweet.db")
+		if err := os.WriteFile(file, data, 0644); err != nil {
+			t.Fatalf("failed to copy fixture: %v", err)
+		}
+
+		got, err := importFile(statsDb, file)
+		if err != nil {
+			t.Fatalf("failed to import: %v", err)
+		}
+		if want := (result{added: 3, exercises: 1}); got != want {
+			t.Errorf("got %v, want %v", got, want)
+		}
+		after, err := os.ReadFile(file)
+		if err != nil {
+			t.Fatalf("failed to read imported database: %v", err)
+		}
+		if !bytes.Equal(data, after) {
+			t.Errorf("importing the database changed it")
+		}
+	})
+
+	t.Run("missing file", func(t *testing.T) {
+		if _, err := importFile(statsDb, path.Join(t.TempDir(), "nope.csv")); err == nil {
+			t.Errorf("wanted error, got nil")
+		}
+	})
+
+	reps, err := db.GetReps(statsDb, db.RepFilter{})
+	if err != nil {
+		t.Fatalf("failed to get reps: %v", err)
+	}
+	if len(reps) != 6 {
+		t.Errorf("got %d reps, want 6", len(reps))
+	}
+}
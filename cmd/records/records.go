@@ -0,0 +1,125 @@
+/*
+records - Prints your personal bests and practice streaks.
+
+Usage:
+
+	sweet records
+*/
+package records
+
+import (
+	"database/sql"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/NicksPatties/sweet/cmd/stats"
+	"github.com/NicksPatties/sweet/constants"
+	"github.com/NicksPatties/sweet/db"
+	tw "github.com/olekukonko/tablewriter"
+	"github.com/spf13/cobra"
+)
+
+var Cmd = &cobra.Command{
+	Use:   "records",
+	Short: "Print your personal bests and practice streaks",
+	Long: "Print your best wpm and accuracy overall, for each language, and for each exercise, and the fastest rep of each exercise.\n" +
+		"Also prints how many days in a row you've practiced.",
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		statsDb, err := db.SweetDb()
+		if err != nil {
+			return fmt.Errorf("failed to connect to database: %s", err)
+		}
+		defer statsDb.Close()
+		return render(cmd.OutOrStdout(), statsDb, time.Now())
+	},
+}
+
+func formatDur(d time.Duration) string {
+	if d == 0 {
+		return "-"
+	}
+	return d.Round(time.Millisecond).String()
+}
+
+// Writes a table of bests, labeled by the first column. Durations
+// are only comparable between reps of the same exercise, so the
+// fastest rep is only shown if the bests are of exercises.
+func renderBests(w io.Writer, title string, label string, bests []db.Best, name func(db.Best) string, fastest bool) {
+	fmt.Fprintf(w, "%s:\n", title)
+	table := tw.NewWriter(w)
+	header := []string{label, "reps", "wpm", "acc"}
+	if fastest {
+		header = append(header, "fastest")
+	}
+	table.SetHeader(header)
+	table.SetAutoFormatHeaders(false)
+	for _, b := range bests {
+		row := []string{
+			name(b),
+			fmt.Sprint(b.Reps),
+			fmt.Sprintf("%.f", b.Wpm),
+			fmt.Sprintf("%.2f%%", b.Acc),
+		}
+		if fastest {
+			row = append(row, formatDur(b.Dur))
+		}
+		table.Append(row)
+	}
+	table.Render()
+}
+
+// Writes the practice streaks and personal bests.
+func render(w io.Writer, statsDb *sql.DB, now time.Time) error {
+	overall, err := db.GetBest(statsDb, db.RepFilter{})
+	if err != nil {
+		return err
+	}
+	if overall.Reps == 0 {
+		fmt.Fprintln(w, "no reps yet, so no records to show")
+		return nil
+	}
+	langs, err := db.GetBests(statsDb, constants.LANGUAGE)
+	if err != nil {
+		return err
+	}
+	exercises, err := db.GetBests(statsDb, constants.HASH)
+	if err != nil {
+		return err
+	}
+	starts, err := db.GetStarts(statsDb)
+	if err != nil {
+		return err
+	}
+
+	ss := db.Streaks(starts)
+	longest := db.LongestStreak(ss)
+	fmt.Fprintf(w, "current streak:  %s\n", db.CurrentStreak(ss, now))
+	fmt.Fprintf(w, "longest streak:  %s (%s to %s)\n\n", longest,
+		longest.Start.Format(time.DateOnly), longest.End().Format(time.DateOnly))
+
+	renderBests(w, "overall", "", []db.Best{overall}, func(db.Best) string { return "all reps" }, false)
+	fmt.Fprintln(w)
+	renderBests(w, "by language", "lang", langs, func(b db.Best) string { return b.Group }, false)
+	fmt.Fprintln(w)
+	renderBests(w, "by exercise", "name", exercises, exerciseLabel(exercises), true)
+	return nil
+}
+
+// Returns the label of each exercise's bests. If exercises of
+// different hashes share a name, then the exercise was changed
+// between them, so each version is labeled with its short hash,
+// the same as in stats.
+func exerciseLabel(exercises []db.Best) func(db.Best) string {
+	versions := map[string]int{}
+	for _, b := range exercises {
+		versions[b.Name]++
+	}
+	return func(b db.Best) string {
+		if versions[b.Name] > 1 {
+			return fmt.Sprintf("%s (%s)", b.Name, stats.ShortHash(b.Group))
+		}
+		return b.Name
+	}
+}
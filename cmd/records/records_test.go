@@ -0,0 +1,107 @@
+package records
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/NicksPatties/sweet/db"
+)
+
+func date(day int, hour int) time.Time {
+	return time.Date(2024, 10, day, hour, 0, 0, 0, time.Local)
+}
+
+func mockRep(hash string, lang string, start time.Time, wpm float64, acc float64, dur time.Duration) db.Rep {
+	return db.Rep{
+		Hash:  hash,
+		Name:  hash + "." + lang,
+		Lang:  lang,
+		Start: start,
+		End:   start.Add(dur),
+		Wpm:   wpm,
+		Acc:   acc,
+		Dur:   dur,
+	}
+}
+
+func TestRender(t *testing.T) {
+	t.Setenv("SWEET_DB_LOCATION", t.TempDir())
+	statsDb, err := db.SweetDb()
+	if err != nil {
+		t.Fatalf("failed to open database: %v", err)
+	}
+	defer statsDb.Close()
+
+	var out bytes.Buffer
+	if err := render(&out, statsDb, date(3, 12)); err != nil {
+		t.Fatalf("failed to render: %v", err)
+	}
+	if !strings.Contains(out.String(), "no reps yet") {
+		t.Errorf("wanted a message without reps, got:\n%s", out.String())
+	}
+
+	for _, rep := range []db.Rep{
+		mockRep("a", "go", date(1, 9), 80, 100, 10*time.Second),
+		mockRep("b", "py", date(2, 9), 60, 95, 20*time.Second),
+	} {
+		if _, err := db.InsertRep(statsDb, rep); err != nil {
+			t.Fatalf("failed to insert rep: %v", err)
+		}
+	}
+	out.Reset()
+	if err := render(&out, statsDb, date(3, 12)); err != nil {
+		t.Fatalf("failed to render: %v", err)
+	}
+	got := out.String()
+	for _, want := range []string{
+		"current streak:  2 days",
+		"longest streak:  2 days (2024-10-01 to 2024-10-02)",
+		"a.go", "b.py", "10s", "20s",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("wanted %q in:\n%s", want, got)
+		}
+	}
+
+	// a changed exercise's versions are told apart by their hashes
+	changed := mockRep("a1b2c3d4e5", "go", date(3, 9), 70, 90, 15*time.Second)
+	changed.Name = "a.go"
+	if _, err := db.InsertRep(statsDb, changed); err != nil {
+		t.Fatalf("failed to insert rep: %v", err)
+	}
+	out.Reset()
+	if err := render(&out, statsDb, date(3, 12)); err != nil {
+		t.Fatalf("failed to render: %v", err)
+	}
+	got = out.String()
+	for _, want := range []string{"a.go (a)", "a.go (a1b2c3d)", "b.py"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("wanted %q in:\n%s", want, got)
+		}
+	}
+
+	// files with the same text are different exercises
+	copied := mockRep("b", "py", date(3, 10), 30, 80, 30*time.Second)
+	copied.Name = "copy.py"
+	if _, err := db.InsertRep(statsDb, copied); err != nil {
+		t.Fatalf("failed to insert rep: %v", err)
+	}
+	out.Reset()
+	if err := render(&out, statsDb, date(3, 12)); err != nil {
+		t.Fatalf("failed to render: %v", err)
+	}
+	got = out.String()
+	for _, want := range []string{"b.py", "copy.py", "30s"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("wanted %q in:\n%s", want, got)
+		}
+	}
+
+	// durations of different exercises aren't comparable
+	beforeExercises, _, _ := strings.Cut(got, "by exercise:")
+	if strings.Contains(beforeExercises, "fastest") {
+		t.Errorf("only exercises should have a fastest rep, got:\n%s", got)
+	}
+}
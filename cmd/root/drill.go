@@ -0,0 +1,202 @@
+package root
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+	"strings"
+	"time"
+
+	consts "github.com/NicksPatties/sweet/constants"
+	"github.com/NicksPatties/sweet/db"
+	"github.com/NicksPatties/sweet/event"
+
+	"github.com/spf13/cobra"
+)
+
+// The number of tokens on each line of a drill.
+const drillTokensPerLine = 6
+
+// How much more likely a token is to be picked for a drill
+// if it contains all of the user's missed keys. Tokens
+// without any missed keys have a weight of 1.
+const drillMissWeight = 10.0
+
+// Code-like tokens that drills are made of. Between them, they
+// cover every key on the keyboard, so that every missed key has
+// a few tokens to practice with.
+var drillTokens = []string{
+	"if", "else", "for", "range", "return", "func", "var", "const",
+	"type", "struct", "import", "package", "switch", "case", "default",
+	"break", "continue", "nil", "true", "false", "self", "this", "def",
+	"class", "let", "async", "await", "null", "None", "yield", "lambda",
+	"err", "ctx", "len", "cap", "append", "make", "new", "map", "string",
+	"int", "bool", "byte", "key", "value", "query", "json", "buf", "fmt",
+	"os", "io", "wg", "mux", "xhr", "zip", "quit", "jump", "vec", "kwargs",
+	"i", "j", "k", "x", "y", "z",
+	"Query", "Zone", "MAX_SIZE", "JSONKey", "XML", "GetValue", "NewReader",
+	"Buffer", "HTTPClient", "Vector", "WaitGroup", "UUID", "KeyError",
+	"fooBar", "foo_bar", "_private", "__init__",
+	":=", "==", "!=", "<=", ">=", "&&", "||", "->", "=>", "++", "--",
+	"+=", "-=", "*=", "/=", "%=", "<<", ">>", "::", "...", "^", "~", "!",
+	"?", "@", "#", "$", "%", "&", "*", "|", "\\", ";", ":", ",", ".",
+	"()", "[]", "{}", "<>", "\"\"", "''", "``", "/*", "*/", "//",
+	"0", "1", "2", "3", "4", "5", "6", "7", "8", "9", "42", "100", "0x1f",
+	"fmt.Println()", "x[i]", "m[key]", "a, b", "i++", "&x", "*p",
+	"a->b", "f(x)", "arr[0]", "#include", "@Override", "$HOME",
+	"~/.config", "<div>", "</div>", "x ? y : z", "'\\n'", "\"%s\"",
+	"`${x}`", "!ok", "-1", "+1", "=", "<", ">", "-", "+", "/", "(", ")",
+	"[", "]", "{", "}", "'", "\"", "`",
+}
+
+var drillCmd = &cobra.Command{
+	Use:   "drill",
+	Short: "Practice the keys you miss the most",
+	Long: "Generates an exercise out of code-like tokens that contain the keys you've\n" +
+		"missed the most in all of your previous reps, and runs it.",
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		lines, err := cmd.Flags().GetUint("lines")
+		if err != nil {
+			return err
+		}
+		if lines == 0 {
+			return errors.New("lines flag must be greater than 0")
+		}
+		misses, err := missedKeysOf(db.RepFilter{})
+		if err != nil {
+			return err
+		}
+		if len(misses) == 0 {
+			return errors.New("no missed keys found. complete a few exercises first!")
+		}
+		random := rand.New(rand.NewSource(time.Now().UnixNano()))
+		text := generateDrill(misses, lines, random)
+		viewOptions, err := viewOptionsFromArgs(cmd, text)
+		if err != nil {
+			return err
+		}
+		exerciseOptions, err := exerciseOptionsFromArgs(cmd)
+		if err != nil {
+			return err
+		}
+		run(consts.DRILL_NAME, text, viewOptions, exerciseOptions)
+		return nil
+	},
+}
+
+// Counts the number of times each key was missed across
+// the reps in the database that match the filter.
+func missedKeysOf(filter db.RepFilter) (map[string]int, error) {
+	statsDb, err := db.SweetDb()
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to database: %s", err)
+	}
+	defer statsDb.Close()
+
+	reps, err := db.GetReps(statsDb, filter)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get reps: %s", err)
+	}
+	if err := db.LoadEvents(statsDb, reps); err != nil {
+		return nil, fmt.Errorf("failed to get events: %s", err)
+	}
+
+	var events []event.Event
+	for _, rep := range reps {
+		events = append(events, rep.Events...)
+	}
+	return missedKeys(events), nil
+}
+
+// Returns the tokens that a drill can be made of. If a missed key
+// isn't in any of the drill tokens, then the key becomes a token,
+// so it can still be practiced.
+func drillTokensFor(misses map[string]int) []string {
+	tokens := append([]string{}, drillTokens...)
+	for key := range misses {
+		if key == "enter" || key == "space" {
+			continue
+		}
+		found := false
+		for _, token := range drillTokens {
+			if strings.Contains(token, key) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			tokens = append(tokens, key)
+		}
+	}
+	return tokens
+}
+
+// Returns how likely a token is to be picked for a drill. Each
+// rune of the token adds its share of the total misses to the weight.
+func drillTokenWeight(token string, misses map[string]int) float64 {
+	return 1.0 + drillMissWeight*missShare(token, misses)
+}
+
+// Adds up each rune's share of the total misses.
+func missShare(text string, misses map[string]int) float64 {
+	total := 0
+	for _, count := range misses {
+		total += count
+	}
+	if total == 0 {
+		return 0
+	}
+	share := 0.0
+	for _, rn := range text {
+		share += float64(misses[event.RuneToEventExpected(rn)]) / float64(total)
+	}
+	return share
+}
+
+// Picks an index at random, where each index is as likely
+// to be picked as its share of the total weight.
+func weightedPick(weights []float64, random *rand.Rand) int {
+	total := 0.0
+	for _, w := range weights {
+		total += w
+	}
+	r := random.Float64() * total
+	for i, w := range weights {
+		if r < w {
+			return i
+		}
+		r -= w
+	}
+	return len(weights) - 1
+}
+
+// Generates the text of a drill. Tokens are picked at random,
+// with the tokens containing the most missed keys being picked
+// more often than the others.
+func generateDrill(misses map[string]int, lines uint, random *rand.Rand) (text string) {
+	tokens := drillTokensFor(misses)
+	weights := make([]float64, len(tokens))
+	for i, token := range tokens {
+		weights[i] = drillTokenWeight(token, misses)
+	}
+
+	pick := func() string {
+		return tokens[weightedPick(weights, random)]
+	}
+
+	for l := uint(0); l < lines; l++ {
+		line := []string{}
+		for t := 0; t < drillTokensPerLine; t++ {
+			line = append(line, pick())
+		}
+		text += strings.Join(line, " ") + "\n"
+	}
+	return
+}
+
+func init() {
+	drillCmd.Flags().UintP("lines", "n", 5, "the number of lines in the drill")
+	setExerciseFlags(drillCmd)
+	drillCmd.Flags().SortFlags = false
+}
@@ -0,0 +1,104 @@
+package root
+
+import (
+	"math/rand"
+	"strings"
+	"testing"
+)
+
+func Test_drillTokensFor(t *testing.T) {
+	testCases := []struct {
+		name    string
+		misses  map[string]int
+		wantLen int
+	}{
+		{
+			name:    "missed keys already in the tokens",
+			misses:  map[string]int{"q": 2, ";": 1},
+			wantLen: len(drillTokens),
+		},
+		{
+			name:    "enter and space are not tokens",
+			misses:  map[string]int{"enter": 2, "space": 1},
+			wantLen: len(drillTokens),
+		},
+		{
+			name:    "missed key that isn't in any token",
+			misses:  map[string]int{"é": 1},
+			wantLen: len(drillTokens) + 1,
+		},
+	}
+
+	for _, tc := range testCases {
+		got := drillTokensFor(tc.misses)
+		if len(got) != tc.wantLen {
+			t.Errorf("%s: got %d tokens, want %d", tc.name, len(got), tc.wantLen)
+		}
+	}
+}
+
+func Test_drillTokenWeight(t *testing.T) {
+	misses := map[string]int{"q": 3, ";": 1}
+	testCases := []struct {
+		name  string
+		token string
+		want  float64
+	}{
+		{
+			name:  "no missed keys",
+			token: "if",
+			want:  1.0,
+		},
+		{
+			name:  "one missed key",
+			token: "quit",
+			want:  1.0 + drillMissWeight*0.75,
+		},
+		{
+			name:  "all missed keys",
+			token: "q;",
+			want:  1.0 + drillMissWeight,
+		},
+	}
+
+	for _, tc := range testCases {
+		got := drillTokenWeight(tc.token, misses)
+		if got != tc.want {
+			t.Errorf("%s: got %.2f, want %.2f", tc.name, got, tc.want)
+		}
+	}
+}
+
+func Test_generateDrill(t *testing.T) {
+	misses := map[string]int{"q": 10}
+	random := rand.New(rand.NewSource(1))
+	got := generateDrill(misses, 40, random)
+
+	lines := strings.Split(strings.TrimSuffix(got, "\n"), "\n")
+	if len(lines) != 40 {
+		t.Fatalf("got %d lines, want 40", len(lines))
+	}
+
+	// Without any missed keys, only a few tokens contain a 'q'.
+	withQ := 0
+	for _, token := range drillTokens {
+		if strings.Contains(token, "q") {
+			withQ++
+		}
+	}
+	uniformShare := float64(withQ) / float64(len(drillTokens))
+
+	picked, pickedWithQ := 0, 0
+	for _, line := range lines {
+		for _, token := range strings.Split(line, " ") {
+			picked++
+			if strings.Contains(token, "q") {
+				pickedWithQ++
+			}
+		}
+	}
+	gotShare := float64(pickedWithQ) / float64(picked)
+	if gotShare <= uniformShare*2 {
+		t.Errorf("tokens with missed keys should be picked more often: got %.2f, uniform %.2f", gotShare, uniformShare)
+	}
+}
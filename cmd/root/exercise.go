@@ -2,8 +2,11 @@ package root
 
 import (
 	"fmt"
+	"math"
 	"os"
+	"strings"
 	"time"
+	"unicode/utf8"
 
 	consts "github.com/NicksPatties/sweet/constants"
 	"github.com/NicksPatties/sweet/db"
@@ -25,6 +28,10 @@ type exerciseModel struct {
 	text string
 
 	// The charcters that the user has typed during this exercise.
+	//
+	// Positions in the text and typed text, such as event indexes,
+	// are counted in runes, not bytes, so each character takes a
+	// single keystroke regardless of its encoded length.
 	typedText string
 
 	startTime time.Time
@@ -34,17 +41,158 @@ type exerciseModel struct {
 	// The user's keystrokes during the exercise
 	events []event.Event
 
+	// The length of a timed exercise. If zero, then the exercise
+	// ends once the whole text has been typed.
+	timeLimit time.Duration
+
+	// The text that's appended to a timed exercise whenever
+	// the user reaches the end of it.
+	feed string
+
+	// True if a timed exercise ended because it ran out of time.
+	timedOut bool
+
+	// If true, mistakes must be corrected before the user
+	// can move on to the next character.
+	strict bool
+
+	// True if the last key typed in a strict exercise was wrong.
+	missed bool
+
+	// How long the user can go without typing before the
+	// exercise pauses itself. If 0, it never pauses itself.
+	idleTimeout time.Duration
+
+	// True while the exercise is paused.
+	paused bool
+
+	// The periods the exercise was paused. Left out of the
+	// exercise's duration and wpm.
+	pauses event.Pauses
+
+	// The token class of each character of the text, used for
+	// syntax highlighting. Nil if the text isn't highlighted.
+	classes []tokenClass
+
+	// The positions of the ghost cursor when racing against
+	// a previous rep. Empty if there's no ghost.
+	ghost []ghostFrame
+
 	viewOptions *viewOptions
 }
 
+// The position of the ghost cursor at a moment of the ghost's rep.
+type ghostFrame struct {
+	// The time since the ghost's first keystroke.
+	at time.Duration
+
+	// The length of the ghost's typed text in runes.
+	pos int
+}
+
+// Sent periodically while a timed exercise, a ghost race, or
+// idle detection is running.
+type tickMsg time.Time
+
+// Returns the time between ticks. Ghosts move more often than
+// the timer changes, so they need to be updated more frequently.
+func (m exerciseModel) tickInterval() time.Duration {
+	if len(m.ghost) > 0 {
+		return 100 * time.Millisecond
+	}
+	return time.Second
+}
+
+// Returns the time until the next tick. Ticks happen an interval
+// after the last one, not on whole seconds of the exercise, so the
+// last tick of a timed exercise happens when its time runs out.
+func (m exerciseModel) untilTick(now time.Time) time.Duration {
+	d := m.tickInterval()
+	if m.timeLimit > 0 {
+		if remaining := m.timeLimit - m.elapsed(now); remaining > 0 && remaining < d {
+			d = remaining
+		}
+	}
+	return d
+}
+
+func (m exerciseModel) tick(now time.Time) tea.Cmd {
+	return tea.Tick(m.untilTick(now), func(t time.Time) tea.Msg {
+		return tickMsg(t)
+	})
+}
+
+// Replays the events of a rep of the text, and records the
+// position of the cursor after each one of them.
+func ghostFrames(text string, events []event.Event, strict bool) (frames []ghostFrame) {
+	if len(events) == 0 {
+		return
+	}
+	replay := exerciseModel{text: text, strict: strict}
+	for _, e := range events {
+		replay = replay.applyEvent(e)
+		frames = append(frames, ghostFrame{
+			at:  e.Ts.Sub(events[0].Ts),
+			pos: utf8.RuneCountInString(replay.typedText),
+		})
+	}
+	return
+}
+
+// Returns the position of the ghost cursor. The ghost starts
+// once the user types their first key, and returns -1 if there's
+// no ghost to race.
+func (m exerciseModel) ghostPos(now time.Time) int {
+	if len(m.ghost) == 0 {
+		return -1
+	}
+	if m.startTime.IsZero() {
+		return 0
+	}
+	elapsed := m.elapsed(now)
+	pos := 0
+	for _, frame := range m.ghost {
+		if frame.at > elapsed {
+			break
+		}
+		pos = frame.pos
+	}
+	return pos
+}
+
 func (m exerciseModel) renderName() string {
 	commentStyle := m.viewOptions.styles.commentStyle
 	commentPrefix := "//"
 	return commentStyle.Render(fmt.Sprintf("%s %s", commentPrefix, m.name))
 }
 
+// Formats a duration like a clock, i.e. "1:05".
+// Partial seconds are rounded up.
+func formatClock(d time.Duration) string {
+	if d < 0 {
+		d = 0
+	}
+	secs := int(math.Ceil(d.Seconds()))
+	return fmt.Sprintf("%d:%02d", secs/60, secs%60)
+}
+
+// Renders the time left in a timed exercise, i.e. "0:42".
+// The countdown doesn't start until the first keystroke.
+func (m exerciseModel) renderTimer(now time.Time) string {
+	remaining := m.timeLimit
+	if !m.startTime.IsZero() {
+		remaining -= m.elapsed(now)
+	}
+	return m.viewOptions.styles.commentStyle.Render(formatClock(remaining))
+}
+
 func (m exerciseModel) renderText() (s string) {
 	lines := util.Lines(m.text)
+	style := m.viewOptions.styles
+	// Show the user that they need to correct their mistake.
+	if m.missed {
+		style.cursorStyle = style.mistakeStyle
+	}
 	typedLines := typedLines(lines, m.typedText)
 
 	windowSize := int(m.viewOptions.windowSize)
@@ -75,6 +223,12 @@ func (m exerciseModel) renderText() (s string) {
 		vignetteLastLine = false
 	}
 
+	ghostPos := m.ghostPos(time.Now())
+	lineStart := 0
+	for i := 0; i < windowStart; i++ {
+		lineStart += utf8.RuneCountInString(lines[i])
+	}
+
 	for i := windowStart; i < windowEnd; i = i + 1 {
 		text := lines[i]
 		var typed *string = nil
@@ -86,7 +240,17 @@ func (m exerciseModel) renderText() (s string) {
 		if vignetteLastLine && i == windowEnd-1 && i != windowStart {
 			shouldVignette = true
 		}
-		line := renderLine(text, typed, m.viewOptions.styles, shouldVignette, isCurrLine)
+		lineLen := utf8.RuneCountInString(text)
+		ghostI := -1
+		if ghostPos >= lineStart && ghostPos < lineStart+lineLen {
+			ghostI = ghostPos - lineStart
+		}
+		var classes []tokenClass
+		if lineStart < len(m.classes) {
+			classes = m.classes[lineStart:min(lineStart+lineLen, len(m.classes))]
+		}
+		lineStart += lineLen
+		line := renderLine(text, typed, style, shouldVignette, isCurrLine, ghostI, classes)
 		if lastLine := i == windowEnd-1; lastLine {
 			line = removeLastNewline(line)
 		}
@@ -99,15 +263,16 @@ func (m exerciseModel) renderText() (s string) {
 // to the exercise characters. If no characters have been typed
 // on a current line, the typedLine will be nil.
 func typedLines(lines []string, typed string) []string {
+	typedRunes := []rune(typed)
 	typedLines := []string{}
 	i := 0
 	for _, line := range lines {
 		str := ""
 		for range line {
-			if i >= len(typed) {
+			if i >= len(typedRunes) {
 				continue
 			}
-			str = str + string(typed[i])
+			str = str + string(typedRunes[i])
 			i = i + 1
 		}
 		if str != "" {
@@ -118,7 +283,7 @@ func typedLines(lines []string, typed string) []string {
 }
 
 func currentLineI(lines []string, typed string) int {
-	typedLen := len(typed)
+	typedLen := utf8.RuneCountInString(typed)
 	for i := range lines {
 		for range lines[i] {
 			if typedLen == 0 {
@@ -143,23 +308,42 @@ func removeLastNewline(str string) string {
 	return str[:i] + str[i+1:]
 }
 
-func renderLine(text string, typedP *string, style styles, vignette bool, currLine bool) (s string) {
+// Renders a line of the exercise. If the ghost cursor is on
+// this line, then ghostI is its index, otherwise it's -1.
+// The classes are the token classes of the line's characters
+// for syntax highlighting, or nil if it isn't highlighted.
+func renderLine(text string, typedP *string, style styles, vignette bool, currLine bool, ghostI int, classes []tokenClass) (s string) {
 	typedStyle := style.typedStyle
 	untypedStyle := style.untypedStyle
 	cursorStyle := style.cursorStyle
 	mistakeStyle := style.mistakeStyle
+	ghostStyle := style.ghostStyle
 
 	if vignette {
 		typedStyle = style.vignetteStyle
 		untypedStyle = style.vignetteStyle
 		cursorStyle = style.vignetteStyle
+		ghostStyle = style.vignetteStyle
+		classes = nil
+	}
+
+	// Returns the style of a character, highlighted
+	// by its token class if there is one.
+	charStyle := func(base lipgloss.Style, i int, typed bool) lipgloss.Style {
+		if i >= len(classes) {
+			return base
+		}
+		return style.highlight(base, classes[i], typed)
 	}
 
 	if typedP == nil {
-		for i, c := range text {
+		for i, c := range []rune(text) {
 			currChar := string(c)
 			if c != '\n' {
-				currChar = untypedStyle.Render(string(c))
+				currChar = charStyle(untypedStyle, i, false).Render(string(c))
+			}
+			if i == ghostI {
+				currChar = renderVisibleRune(ghostStyle, c)
 			}
 			if i == 0 && currLine {
 				currChar = renderVisibleRune(cursorStyle, c)
@@ -169,25 +353,27 @@ func renderLine(text string, typedP *string, style styles, vignette bool, currLi
 		return
 	}
 
-	typed := *typedP
+	typed := []rune(*typedP)
 
-	for i, exRune := range text {
+	for i, exRune := range []rune(text) {
 		typedYet := i > len(typed)
 		isCursor := i == len(typed) && currLine
+		isGhost := i == ghostI
 		isMistake := false
 		if i < len(typed) {
-			typedRune := rune(typed[i])
-			isMistake = typedRune != exRune
+			isMistake = typed[i] != exRune
 		}
 		switch {
-		case typedYet:
-			s += untypedStyle.Render(string(exRune))
 		case isCursor:
 			s += renderVisibleRune(cursorStyle, exRune)
 		case isMistake:
 			s += renderVisibleRune(mistakeStyle, exRune)
+		case isGhost:
+			s += renderVisibleRune(ghostStyle, exRune)
+		case typedYet:
+			s += charStyle(untypedStyle, i, false).Render(string(exRune))
 		default:
-			s += typedStyle.Render(string(exRune))
+			s += charStyle(typedStyle, i, true).Render(string(exRune))
 		}
 	}
 	return
@@ -204,20 +390,20 @@ func renderVisibleRune(style lipgloss.Style, exRune rune) (s string) {
 }
 
 func (m exerciseModel) addRuneToTypedText(rn rune) exerciseModel {
-	if len(m.typedText) == len(m.text) {
+	text := []rune(m.text)
+	idx := utf8.RuneCountInString(m.typedText)
+	if idx >= len(text) {
 		return m
 	}
 
-	idx := len(m.typedText)
-
 	// If the next character is an Enter,
 	// then add the Enter and the following whitespace to the typedText.
 	//
 	// This provides the appearance of auto-indentation while typing.
-	if rune(m.text[idx]) == consts.Enter {
+	if text[idx] == consts.Enter {
 		whiteSpace := []rune{}
-		for i := len(m.typedText) + 1; i < len(m.text) && util.IsWhitespace(rune(m.text[i])); i++ {
-			whiteSpace = append(whiteSpace, rune(m.text[i]))
+		for i := idx + 1; i < len(text) && util.IsWhitespace(text[i]); i++ {
+			whiteSpace = append(whiteSpace, text[i])
 		}
 		m.typedText += string(rn)
 		m.typedText += string(whiteSpace)
@@ -228,46 +414,157 @@ func (m exerciseModel) addRuneToTypedText(rn rune) exerciseModel {
 }
 
 func (m exerciseModel) deleteRuneFromTypedText() exerciseModel {
-	typed := m.typedText
+	typed := []rune(m.typedText)
+	text := []rune(m.text)
 	l := len(typed)
 
 	if l <= 0 {
-		m.typedText = typed
 		return m
 	}
 
-	currRn := rune(typed[l-1])
+	currRn := typed[l-1]
 
 	if !util.IsWhitespace(currRn) {
-		m.typedText = typed[:l-1]
+		m.typedText = string(typed[:l-1])
 		return m
 	}
 
-	m.typedText = typed[:l-1]
-	l = len(m.typedText)
+	m.typedText = string(typed[:l-1])
+	l = l - 1
 	i := 1
 	// move index backwards until a non-whitespace rune is found
-	for ; util.IsWhitespace(rune(m.text[l-i])); i++ {
+	for ; l-i >= 0 && util.IsWhitespace(text[l-i]); i++ {
 	}
-	currRn = rune(m.text[l-i])
-	if currRn == consts.Enter {
+	if l-i >= 0 && text[l-i] == consts.Enter {
 		// remove all runes up to and including the newline rune
-		m.typedText = typed[:l-i]
+		m.typedText = string(typed[:l-i])
+	}
+	return m
+}
+
+// Appends the feed text to the end of the exercise, so a timed
+// exercise can continue after the user types all of its text.
+func (m exerciseModel) feedText() exerciseModel {
+	if !strings.HasSuffix(m.text, "\n") {
+		m.text += "\n"
+	}
+	m.text += m.feed
+	if m.classes != nil {
+		m.classes = tokenClasses(m.name, m.text)
 	}
 	return m
 }
 
+// Deletes the last typed character. In strict mode, a missed key
+// was never typed, so backspace only clears the miss instead.
+func (m exerciseModel) backspace() exerciseModel {
+	if m.strict && m.missed {
+		m.missed = false
+		return m
+	}
+	return m.deleteRuneFromTypedText()
+}
+
+// Applies a recorded event to the typed text, as if the user
+// typed it again. Used to replay previous reps.
+func (m exerciseModel) applyEvent(e event.Event) exerciseModel {
+	if e.Typed == "backspace" {
+		m = m.backspace()
+	} else if m.strict && e.Typed != e.Expected {
+		m.missed = true
+	} else {
+		m.missed = false
+		m = m.addRuneToTypedText(event.EventTypedToRune(e.Typed))
+		if m.feed != "" && m.finished() {
+			m = m.feedText()
+		}
+	}
+	m.events = append(m.events, e)
+	return m
+}
+
+// Returns the time spent on the exercise since the first
+// keystroke, not including the time spent paused.
+func (m exerciseModel) elapsed(now time.Time) time.Duration {
+	return now.Sub(m.startTime) - m.pauses.Before(now)
+}
+
+// Returns true if a timed exercise has run out of time.
+// Untimed exercises and exercises that haven't started yet
+// never run out of time.
+func (m exerciseModel) timeUp(now time.Time) bool {
+	if m.timeLimit == 0 || m.startTime.IsZero() {
+		return false
+	}
+	return m.elapsed(now) >= m.timeLimit
+}
+
+// Returns true if the user hasn't typed anything for longer
+// than the idle timeout. Resuming counts as activity, so the
+// time before the last pause ended isn't counted again.
+func (m exerciseModel) idle(now time.Time) bool {
+	if m.idleTimeout == 0 || m.paused || len(m.events) == 0 {
+		return false
+	}
+	return now.Sub(m.lastActive()) >= m.idleTimeout
+}
+
+// Returns the time of the last keystroke, or the end of the
+// last pause if the exercise was resumed after it.
+func (m exerciseModel) lastActive() time.Time {
+	last := m.events[len(m.events)-1].Ts
+	if len(m.pauses) > 0 && m.pauses[len(m.pauses)-1].End.After(last) {
+		last = m.pauses[len(m.pauses)-1].End
+	}
+	return last
+}
+
+// Pauses the exercise, starting at the given time. Pauses never
+// overlap, so a pause starts no earlier than the last one ended.
+func (m exerciseModel) pause(at time.Time) exerciseModel {
+	if len(m.pauses) > 0 && at.Before(m.pauses[len(m.pauses)-1].End) {
+		at = m.pauses[len(m.pauses)-1].End
+	}
+	m.paused = true
+	m.pauses = append(m.pauses, event.Pause{Start: at})
+	return m
+}
+
+// Resumes a paused exercise at the given time.
+func (m exerciseModel) resume(at time.Time) exerciseModel {
+	m.paused = false
+	// copy the pauses, since other models may share them
+	pauses := make(event.Pauses, len(m.pauses))
+	copy(pauses, m.pauses)
+	pauses[len(pauses)-1].End = at
+	m.pauses = pauses
+	return m
+}
+
+// Returns the rune at index i of the exercise's text, or 0 if
+// the index is past the end of the text. This happens when the
+// last character of the exercise is typed incorrectly.
+func (m exerciseModel) runeAt(i int) rune {
+	text := []rune(m.text)
+	if i < 0 || i >= len(text) {
+		return 0
+	}
+	return text[i]
+}
+
 func (m exerciseModel) finished() bool {
 	// If the user hasn't reached the end of the exercise,
 	// then they're not done yet.
-	l := len(m.text)
-	if len(m.typedText) < l {
+	text := []rune(m.text)
+	typed := []rune(m.typedText)
+	l := len(text)
+	if len(typed) < l {
 		return false
 	}
 
 	// Handle the case where the user types the last character incorrectly
-	exLast := rune(m.text[l-1])
-	typedLast := rune(m.typedText[l-1])
+	exLast := text[l-1]
+	typedLast := typed[l-1]
 
 	if exLast != typedLast {
 		return false
@@ -278,19 +575,30 @@ func (m exerciseModel) finished() bool {
 // Converts the exercise model to a Rep, in preparation for
 // inserting it into the database.
 func (m exerciseModel) Rep() db.Rep {
+	// Timed exercises may have been fed more text than the
+	// exercise contains, so use the original text instead.
+	exerciseText := m.text
+	if m.feed != "" {
+		exerciseText = m.feed
+	}
+	// Leave the pauses out of the duration and wpm.
+	active := event.RemovePauses(m.events, m.pauses)
 	return db.Rep{
-		Hash:   util.MD5Hash(m.text),
+		Hash:   util.MD5Hash(exerciseText),
 		Start:  m.events[0].Ts,
 		End:    m.events[len(m.events)-1].Ts,
 		Name:   m.name,
 		Lang:   util.Lang(m.name),
-		Wpm:    wpm(m.events),
-		Raw:    wpmRaw(m.events),
-		Dur:    duration(m.events),
+		Wpm:    wpm(active),
+		Raw:    wpmRaw(active),
+		Dur:    duration(active),
 		Acc:    accuracy(m.events),
 		Miss:   numMistakes(m.events),
 		Errs:   numUncorrectedErrors(m.events),
 		Events: m.events,
+		Lim:    m.timeLimit,
+		Strict: m.strict,
+		Pauses: m.pauses,
 	}
 }
 
@@ -299,56 +607,113 @@ func (m exerciseModel) Init() tea.Cmd {
 }
 
 func (m exerciseModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	if t, ok := msg.(tickMsg); ok {
+		if m.timeUp(time.Time(t)) {
+			m.endTime = time.Time(t)
+			m.timedOut = true
+			return m, tea.Quit
+		}
+		// The idle time is left out of the rep, so the pause
+		// starts at the user's last keystroke or resume.
+		if m.idle(time.Time(t)) {
+			m = m.pause(m.lastActive())
+		}
+		return m, m.tick(time.Time(t))
+	}
 	keyMsg, ok := msg.(tea.KeyMsg)
 	if !ok {
 		return m, nil
 	}
+	if m.paused {
+		if keyMsg.Type == tea.KeyCtrlC {
+			m.quitEarly = true
+			return m, tea.Quit
+		}
+		// Any key resumes the exercise, but isn't typed.
+		m = m.resume(time.Now())
+		return m, nil
+	}
+	if m.timeUp(time.Now()) {
+		m.endTime = time.Now()
+		m.timedOut = true
+		return m, tea.Quit
+	}
+	var cmd tea.Cmd
 	var currTyped string
-	currI := len(m.typedText)
-	currExpected := event.RuneToEventExpected(rune(m.text[currI]))
+	currI := utf8.RuneCountInString(m.typedText)
+	currRune := m.runeAt(currI)
+	currExpected := ""
+	if currRune != 0 {
+		currExpected = event.RuneToEventExpected(currRune)
+	}
 	switch keyMsg.Type {
 	case tea.KeyCtrlC:
 		m.quitEarly = true
 		return m, tea.Quit
+	case tea.KeyEsc:
+		if !m.startTime.IsZero() {
+			m = m.pause(time.Now())
+		}
 	case tea.KeyBackspace:
 		currTyped = event.TeaKeyMsgToEventTyped(keyMsg)
-		m = m.deleteRuneFromTypedText()
+		m = m.backspace()
 		// Create delete event and add it to events
 		m.events = append(m.events, event.NewEvent("backspace", "", currI))
 	case tea.KeyRunes, tea.KeySpace, tea.KeyEnter:
 		currTyped = event.TeaKeyMsgToEventTyped(keyMsg)
 		if m.startTime.IsZero() {
 			m.startTime = time.Now()
+			if m.timeLimit > 0 || len(m.ghost) > 0 || m.idleTimeout > 0 {
+				cmd = m.tick(m.startTime)
+			}
 		}
-		if keyMsg.Type == tea.KeyEnter {
-			m = m.addRuneToTypedText(consts.Enter)
-		} else {
-			m = m.addRuneToTypedText(keyMsg.Runes[0])
+		typedRune := consts.Enter
+		if keyMsg.Type != tea.KeyEnter {
+			typedRune = keyMsg.Runes[0]
 		}
 		m.events = append(m.events, event.NewEvent(currTyped, currExpected, currI))
+		// In strict mode, wrong keys are recorded, but the
+		// user stays on the same character until it's correct.
+		if m.strict && typedRune != currRune {
+			m.missed = true
+			return m, cmd
+		}
+		m.missed = false
+		m = m.addRuneToTypedText(typedRune)
 		if m.finished() {
+			if m.timeLimit > 0 {
+				m = m.feedText()
+				return m, cmd
+			}
 			m.endTime = time.Now()
 			return m, tea.Quit
 		}
 	}
-	return m, nil
+	return m, cmd
 }
 
 // Displays the text for the typing exercise.
 // Hides the view once the exercise is complete or the user quits early.
 func (m exerciseModel) View() (s string) {
-	if !m.finished() {
+	if !m.finished() && !m.timedOut {
 		s += "\n"
 		s += m.renderName()
+		if m.timeLimit > 0 {
+			s += " " + m.renderTimer(time.Now())
+		}
+		if m.paused {
+			s += " " + m.viewOptions.styles.commentStyle.Render("paused, press any key to resume")
+		}
 		s += "\n\n"
 		s += m.renderText()
 		s += "\n\n"
 
-		currKeyI := len(m.typedText)
-		currKey := m.text[currKeyI]
-		s += qwerty.render(string(currKey))
+		currKey := m.runeAt(utf8.RuneCountInString(m.typedText))
+		layout := m.viewOptions.layout
+		keyboardStyle := m.viewOptions.styles.keyboardStyle
+		s += layout.Render(string(currKey), keyboardStyle)
 		s += "\n"
-		s += renderFingers(qwerty.fingersMargin, '*', rune(currKey))
+		s += layout.RenderFingers('*', currKey, keyboardStyle)
 	}
 	return
 }
@@ -360,9 +725,9 @@ func (m exerciseModel) View() (s string) {
 // 2. Depending on the outcome of the exercise, either completes
 // or returns an error.
 //
-// 3. If the exercise is completed, gather the results, print them, and
-// save them to the database
-func run(name string, text string, options *viewOptions) {
+// 3. If the exercise is completed, or a timed exercise runs out of time,
+// gather the results, print them, and save them to the database
+func run(name string, text string, options *viewOptions, exOptions *exerciseOptions) {
 	newModel := exerciseModel{
 		name:        name,
 		text:        text,
@@ -371,8 +736,25 @@ func run(name string, text string, options *viewOptions) {
 		startTime:   time.Time{},
 		endTime:     time.Time{},
 		events:      []event.Event{},
+		timeLimit:   exOptions.timeLimit,
+		strict:      exOptions.strict,
+		idleTimeout: exOptions.idleTimeout,
 		viewOptions: options,
 	}
+	if options.highlight {
+		newModel.classes = tokenClasses(name, text)
+	}
+	if exOptions.timeLimit > 0 {
+		newModel.feed = text
+	}
+	var ghost *db.Rep
+	if exOptions.ghost {
+		ghost = fastestRep(text)
+		if ghost != nil {
+			events := event.RemovePauses(ghost.Events, ghost.Pauses)
+			newModel.ghost = ghostFrames(text, events, ghost.Strict)
+		}
+	}
 	teaModel, err := tea.NewProgram(newModel).Run()
 	if err != nil {
 		fmt.Printf("Error running typing exercise: %v\n", err)
@@ -389,13 +771,27 @@ func run(name string, text string, options *viewOptions) {
 
 	rep := exModel.Rep()
 
-	printExerciseResults(rep)
-
 	// open connection to db once exercise is complete
 	statsDb, err := db.SweetDb()
 	if err != nil {
 		fmt.Println(err)
 	}
+
+	printExerciseResults(rep, options.layout)
+	if ghost != nil {
+		printGhostResults(rep, *ghost)
+	}
+	// compare to the previous reps before this one is saved
+	news, err := db.NewRecords(statsDb, rep)
+	if err != nil {
+		fmt.Printf("Error checking your records: %v\n", err)
+	}
+	printRecordResults(news)
+	last, err := lastHash(statsDb, rep.Name)
+	if err != nil {
+		fmt.Printf("Error checking if the exercise changed: %v\n", err)
+	}
+	printChangedWarning(rep, last)
 	// insert the row into the database
 	var repId int64
 	repId, err = db.InsertRep(statsDb, rep)
@@ -404,4 +800,28 @@ func run(name string, text string, options *viewOptions) {
 	} else {
 		fmt.Printf("Rep %d saved to the database! Keep it up!\n", repId)
 	}
+	// save the text, so the rep can be replayed
+	if err = db.InsertExercise(statsDb, rep.Hash, name, text); err != nil {
+		fmt.Printf("Error saving exercise text to the database: %v\n", err)
+	}
+}
+
+// Gets the fastest previous rep of the text to race against.
+// If there isn't one, the user races without a ghost.
+func fastestRep(text string) *db.Rep {
+	statsDb, err := db.SweetDb()
+	if err != nil {
+		fmt.Println(err)
+		return nil
+	}
+	defer statsDb.Close()
+	rep, err := db.GetFastestRep(statsDb, util.MD5Hash(text))
+	if err != nil {
+		fmt.Printf("Error getting a ghost from the database: %v\n", err)
+		return nil
+	}
+	if rep == nil {
+		fmt.Println("No previous reps of this exercise to race against. Racing without a ghost.")
+	}
+	return rep
 }
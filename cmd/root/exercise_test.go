@@ -6,8 +6,10 @@ import (
 
 	consts "github.com/NicksPatties/sweet/constants"
 	"github.com/NicksPatties/sweet/event"
+	"github.com/NicksPatties/sweet/keyboard"
 	"github.com/NicksPatties/sweet/util"
 
+	tea "github.com/charmbracelet/bubbletea"
 	lg "github.com/charmbracelet/lipgloss"
 	"github.com/muesli/termenv"
 )
@@ -17,8 +19,11 @@ var renderBytes = util.RenderBytes
 var mockViewOptions = &viewOptions{
 	styles:     defaultStyles(),
 	windowSize: 0,
+	layout:     mockLayout,
 }
 
+var mockLayout, _ = keyboard.Get(keyboard.DefaultLayout)
+
 func Test_renderName(t *testing.T) {
 	testModel := exerciseModel{
 		name:        "exercise.go",
@@ -148,6 +153,12 @@ func Test_renderText_cursorPosition(t *testing.T) {
 			typed:    "def main:\n  print('hello')\n",
 			want:     "def main:\n  print('hello')\n" + util.Red("f") + "unc yeah",
 		},
+		{
+			testName: "multi-byte characters",
+			text:     "λ → x\n// 日本",
+			typed:    "λ → x\n// 日",
+			want:     "λ → x\n// 日" + util.Red("本"),
+		},
 	}
 
 	for _, tc := range testCases {
@@ -466,6 +477,15 @@ func Test_typedLines(t *testing.T) {
 			typed: "one\ntw",
 			want:  []string{"one\n", "tw"},
 		},
+		{
+			name: "multi-byte characters",
+			lines: []string{
+				"λ → x\n",
+				"日本\n",
+			},
+			typed: "λ → x\n日",
+			want:  []string{"λ → x\n", "日"},
+		},
 	}
 
 	for _, tc := range testCases {
@@ -506,6 +526,12 @@ func Test_currentLineI(t *testing.T) {
 			typed: "\n\n\n\n",
 			want:  1,
 		},
+		{
+			name:  "multi-byte characters",
+			text:  "λ → x\n日本",
+			typed: "λ → x\n",
+			want:  1,
+		},
 	}
 
 	for _, tc := range testCases {
@@ -584,6 +610,20 @@ func Test_addRuneToTypedText(t *testing.T) {
 			typedRune: consts.Enter,
 			want:      "def main:\n  ", // two whitespace indentation
 		},
+		{
+			name:      "multi-byte characters",
+			text:      "a → b",
+			typed:     "a ",
+			typedRune: '→',
+			want:      "a →",
+		},
+		{
+			name:      "ignore if typed text with multi-byte characters is the same length of text",
+			text:      "λx",
+			typed:     "λy",
+			typedRune: 'x',
+			want:      "λy",
+		},
 	}
 
 	for _, test := range tt {
@@ -631,6 +671,18 @@ func Test_deleteRuneFromTypedText(t *testing.T) {
 			typed: "def main:\n  ",
 			want:  "def main:",
 		},
+		{
+			name:  "multi-byte character",
+			text:  "a → b",
+			typed: "a →",
+			want:  "a ",
+		},
+		{
+			name:  "whitespace at the start of the exercise",
+			text:  "  asdf",
+			typed: " ",
+			want:  "",
+		},
 	}
 
 	for _, test := range tt {
@@ -676,6 +728,18 @@ func Test_finished(t *testing.T) {
 			typed: "asdq",
 			want:  false,
 		},
+		{
+			name:  "finished: multi-byte characters",
+			text:  "λ → 日本",
+			typed: "λ → 日本",
+			want:  true,
+		},
+		{
+			name:  "not finished: multi-byte characters",
+			text:  "λ → 日本",
+			typed: "λ → 日",
+			want:  false,
+		},
 	}
 
 	for _, test := range tt {
@@ -697,3 +761,722 @@ func Test_finished(t *testing.T) {
 		}
 	}
 }
+
+func Test_timeUp(t *testing.T) {
+	start := time.Date(2024, 10, 7, 13, 46, 47, 0, time.UTC)
+	testCases := []struct {
+		name      string
+		timeLimit time.Duration
+		startTime time.Time
+		pauses    event.Pauses
+		now       time.Time
+		want      bool
+	}{
+		{
+			name:      "untimed exercise",
+			timeLimit: 0,
+			startTime: start,
+			now:       start.Add(time.Hour),
+			want:      false,
+		},
+		{
+			name:      "timed exercise hasn't started yet",
+			timeLimit: time.Minute,
+			startTime: time.Time{},
+			now:       start.Add(time.Hour),
+			want:      false,
+		},
+		{
+			name:      "timed exercise with time left",
+			timeLimit: time.Minute,
+			startTime: start,
+			now:       start.Add(59 * time.Second),
+			want:      false,
+		},
+		{
+			name:      "timed exercise out of time",
+			timeLimit: time.Minute,
+			startTime: start,
+			now:       start.Add(time.Minute),
+			want:      true,
+		},
+		{
+			name:      "timed exercise paused, so there's time left",
+			timeLimit: time.Minute,
+			startTime: start,
+			pauses:    event.Pauses{{Start: start.Add(30 * time.Second), End: start.Add(time.Minute)}},
+			now:       start.Add(time.Minute),
+			want:      false,
+		},
+		{
+			name:      "timed exercise paused until now",
+			timeLimit: time.Minute,
+			startTime: start,
+			pauses:    event.Pauses{{Start: start.Add(30 * time.Second)}},
+			now:       start.Add(time.Hour),
+			want:      false,
+		},
+	}
+
+	for _, tc := range testCases {
+		testModel := exerciseModel{
+			startTime:   tc.startTime,
+			timeLimit:   tc.timeLimit,
+			pauses:      tc.pauses,
+			viewOptions: mockViewOptions,
+		}
+		got := testModel.timeUp(tc.now)
+		if got != tc.want {
+			t.Errorf("%s: want %t, got %t", tc.name, tc.want, got)
+		}
+	}
+}
+
+func Test_untilTick(t *testing.T) {
+	start := time.Date(2024, 10, 7, 13, 46, 47, 0, time.UTC)
+	testCases := []struct {
+		name      string
+		timeLimit time.Duration
+		pauses    event.Pauses
+		now       time.Time
+		want      time.Duration
+	}{
+		{
+			name: "untimed exercise",
+			now:  start.Add(time.Hour),
+			want: time.Second,
+		},
+		{
+			name:      "plenty of time left",
+			timeLimit: 90*time.Second + 500*time.Millisecond,
+			now:       start.Add(10 * time.Second),
+			want:      time.Second,
+		},
+		{
+			name:      "less than a tick left",
+			timeLimit: 90*time.Second + 500*time.Millisecond,
+			now:       start.Add(90 * time.Second),
+			want:      500 * time.Millisecond,
+		},
+		{
+			name:      "less than a tick left after a pause",
+			timeLimit: time.Minute,
+			pauses:    event.Pauses{{Start: start.Add(10 * time.Second), End: start.Add(10*time.Second + 300*time.Millisecond)}},
+			now:       start.Add(time.Minute),
+			want:      300 * time.Millisecond,
+		},
+	}
+
+	for _, tc := range testCases {
+		testModel := exerciseModel{
+			startTime:   start,
+			timeLimit:   tc.timeLimit,
+			pauses:      tc.pauses,
+			viewOptions: mockViewOptions,
+		}
+		if got := testModel.untilTick(tc.now); got != tc.want {
+			t.Errorf("%s: got %s, want %s", tc.name, got, tc.want)
+		}
+	}
+}
+
+func Test_idle(t *testing.T) {
+	start := time.Date(2024, 10, 7, 13, 46, 47, 0, time.UTC)
+	events := []event.Event{{Ts: start, Typed: "a", Expected: "a"}}
+	testCases := []struct {
+		name        string
+		idleTimeout time.Duration
+		events      []event.Event
+		pauses      event.Pauses
+		paused      bool
+		now         time.Time
+		want        bool
+	}{
+		{
+			name:        "idle detection is off",
+			idleTimeout: 0,
+			events:      events,
+			now:         start.Add(time.Hour),
+			want:        false,
+		},
+		{
+			name:        "exercise hasn't started yet",
+			idleTimeout: 30 * time.Second,
+			events:      []event.Event{},
+			now:         start.Add(time.Hour),
+			want:        false,
+		},
+		{
+			name:        "typed recently",
+			idleTimeout: 30 * time.Second,
+			events:      events,
+			now:         start.Add(29 * time.Second),
+			want:        false,
+		},
+		{
+			name:        "hasn't typed for a while",
+			idleTimeout: 30 * time.Second,
+			events:      events,
+			now:         start.Add(30 * time.Second),
+			want:        true,
+		},
+		{
+			name:        "already paused",
+			idleTimeout: 30 * time.Second,
+			events:      events,
+			paused:      true,
+			now:         start.Add(time.Hour),
+			want:        false,
+		},
+		{
+			name:        "resumed recently",
+			idleTimeout: 30 * time.Second,
+			events:      events,
+			pauses:      event.Pauses{{Start: start, End: start.Add(time.Hour)}},
+			now:         start.Add(time.Hour + time.Second),
+			want:        false,
+		},
+		{
+			name:        "hasn't typed for a while since resuming",
+			idleTimeout: 30 * time.Second,
+			events:      events,
+			pauses:      event.Pauses{{Start: start, End: start.Add(time.Hour)}},
+			now:         start.Add(time.Hour + 30*time.Second),
+			want:        true,
+		},
+	}
+
+	for _, tc := range testCases {
+		testModel := exerciseModel{
+			idleTimeout: tc.idleTimeout,
+			events:      tc.events,
+			pauses:      tc.pauses,
+			paused:      tc.paused,
+			viewOptions: mockViewOptions,
+		}
+		got := testModel.idle(tc.now)
+		if got != tc.want {
+			t.Errorf("%s: want %t, got %t", tc.name, tc.want, got)
+		}
+	}
+}
+
+func Test_Update_pause(t *testing.T) {
+	start := time.Now().Add(-time.Hour)
+	testModel := exerciseModel{
+		text:        "asdf",
+		typedText:   "a",
+		startTime:   start,
+		events:      []event.Event{{Ts: start, Typed: "a", Expected: "a"}},
+		idleTimeout: 30 * time.Second,
+		viewOptions: mockViewOptions,
+	}
+
+	// the user walked away after their first keystroke
+	model, _ := testModel.Update(tickMsg(time.Now()))
+	testModel = model.(exerciseModel)
+	if !testModel.paused {
+		t.Fatalf("should pause after being idle")
+	}
+	if !testModel.pauses[0].Start.Equal(start) {
+		t.Errorf("pause should start at the last keystroke, got %s", testModel.pauses[0].Start)
+	}
+
+	// the key that resumes the exercise isn't typed
+	model, _ = testModel.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'s'}})
+	testModel = model.(exerciseModel)
+	if testModel.paused || testModel.typedText != "a" || len(testModel.events) != 1 {
+		t.Fatalf("should resume without typing, got paused %t, typed %q", testModel.paused, testModel.typedText)
+	}
+	if testModel.pauses[0].End.IsZero() {
+		t.Errorf("pause should end when the exercise resumes")
+	}
+
+	// escape pauses the exercise
+	model, _ = testModel.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	testModel = model.(exerciseModel)
+	if !testModel.paused || len(testModel.pauses) != 2 {
+		t.Errorf("escape should pause the exercise, got paused %t with %d pauses", testModel.paused, len(testModel.pauses))
+	}
+}
+
+func Test_Update_resumeAfterIdle(t *testing.T) {
+	start := time.Now().Add(-time.Minute)
+	testModel := exerciseModel{
+		text:        "asdf",
+		typedText:   "a",
+		startTime:   start,
+		events:      []event.Event{{Ts: start, Typed: "a", Expected: "a"}},
+		idleTimeout: 30 * time.Second,
+		viewOptions: mockViewOptions,
+	}
+
+	// idle, then resumed
+	model, _ := testModel.Update(tickMsg(time.Now()))
+	testModel = model.(exerciseModel)
+	model, _ = testModel.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'s'}})
+	testModel = model.(exerciseModel)
+
+	// the next tick shouldn't count the time before the pause again
+	model, _ = testModel.Update(tickMsg(time.Now()))
+	testModel = model.(exerciseModel)
+	if testModel.paused || len(testModel.pauses) != 1 {
+		t.Fatalf("should stay resumed after a tick, got paused %t with %d pauses", testModel.paused, len(testModel.pauses))
+	}
+
+	model, _ = testModel.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'s'}})
+	testModel = model.(exerciseModel)
+	if testModel.typedText != "as" {
+		t.Fatalf("got typed %q, want %q", testModel.typedText, "as")
+	}
+	if rep := testModel.Rep(); rep.Dur < 0 || rep.Dur > time.Second {
+		t.Errorf("got duration %s, want the time since resuming", rep.Dur)
+	}
+}
+
+func Test_pause_overlap(t *testing.T) {
+	start := time.Date(2024, 10, 7, 13, 46, 47, 0, time.UTC)
+	testModel := exerciseModel{
+		pauses: event.Pauses{{Start: start, End: start.Add(time.Minute)}},
+	}
+	testModel = testModel.pause(start)
+	if got := testModel.pauses[1].Start; !got.Equal(start.Add(time.Minute)) {
+		t.Errorf("pause should start when the last one ended, got %s", got)
+	}
+}
+
+func Test_Rep_pauses(t *testing.T) {
+	events := event.ParseEvents(
+		"2024-10-07 13:00:00.000\t0\ta\ta\n" +
+			"2024-10-07 13:00:01.000\t1\ts\ts\n" +
+			"2024-10-07 13:10:01.000\t2\td\td\n" +
+			"2024-10-07 13:10:02.000\t3\tf\tf\n",
+	)
+	pauses := event.Pauses{{
+		Start: events[1].Ts,
+		End:   events[1].Ts.Add(10*time.Minute - time.Second),
+	}}
+	testModel := exerciseModel{
+		name:        "asdf.txt",
+		text:        "asdf",
+		typedText:   "asdf",
+		events:      events,
+		pauses:      pauses,
+		viewOptions: mockViewOptions,
+	}
+
+	rep := testModel.Rep()
+	if rep.Dur != 3*time.Second {
+		t.Errorf("got duration %s, want 3s", rep.Dur)
+	}
+	if want := wpm(event.RemovePauses(events, pauses)); rep.Wpm != want {
+		t.Errorf("got wpm %.2f, want %.2f", rep.Wpm, want)
+	}
+	if len(rep.Pauses) != 1 {
+		t.Errorf("pauses should be saved with the rep, got %v", rep.Pauses)
+	}
+}
+
+func Test_feedText(t *testing.T) {
+	testCases := []struct {
+		name string
+		text string
+		feed string
+		want string
+	}{
+		{
+			name: "text ends with a newline",
+			text: "one\n",
+			feed: "one\n",
+			want: "one\none\n",
+		},
+		{
+			name: "text without a final newline gets one",
+			text: "one",
+			feed: "one",
+			want: "one\none",
+		},
+	}
+
+	for _, tc := range testCases {
+		testModel := exerciseModel{
+			text:        tc.text,
+			feed:        tc.feed,
+			viewOptions: mockViewOptions,
+		}
+		got := testModel.feedText().text
+		if got != tc.want {
+			t.Errorf("%s: want %q, got %q", tc.name, tc.want, got)
+		}
+	}
+}
+
+func Test_renderTimer(t *testing.T) {
+	start := time.Date(2024, 10, 7, 13, 46, 47, 0, time.UTC)
+	testViewOptions := &viewOptions{
+		styles: styles{
+			commentStyle: lg.NewStyle(),
+		},
+	}
+	testCases := []struct {
+		name      string
+		timeLimit time.Duration
+		startTime time.Time
+		now       time.Time
+		want      string
+	}{
+		{
+			name:      "not started yet",
+			timeLimit: 90 * time.Second,
+			startTime: time.Time{},
+			now:       start,
+			want:      "1:30",
+		},
+		{
+			name:      "partway through rounds up",
+			timeLimit: time.Minute,
+			startTime: start,
+			now:       start.Add(17500 * time.Millisecond),
+			want:      "0:43",
+		},
+		{
+			name:      "out of time",
+			timeLimit: time.Minute,
+			startTime: start,
+			now:       start.Add(2 * time.Minute),
+			want:      "0:00",
+		},
+	}
+
+	for _, tc := range testCases {
+		testModel := exerciseModel{
+			startTime:   tc.startTime,
+			timeLimit:   tc.timeLimit,
+			viewOptions: testViewOptions,
+		}
+		got := testModel.renderTimer(tc.now)
+		if got != tc.want {
+			t.Errorf("%s: want %s, got %s", tc.name, tc.want, got)
+		}
+	}
+}
+
+func Test_ghostFrames(t *testing.T) {
+	events := event.ParseEvents(
+		"2024-10-07 13:46:47.000\t0\ta\th\n" +
+			"2024-10-07 13:46:47.500\t1\tbackspace\n" +
+			"2024-10-07 13:46:48.000\t0\th\th\n" +
+			"2024-10-07 13:46:48.250\t1\tenter\tenter",
+	)
+	want := []ghostFrame{
+		{at: 0, pos: 1},
+		{at: 500 * time.Millisecond, pos: 0},
+		{at: time.Second, pos: 1},
+		{at: 1250 * time.Millisecond, pos: 4},
+	}
+	got := ghostFrames("h\n  i\n", events, false)
+	if len(got) != len(want) {
+		t.Fatalf("got %d frames, want %d", len(got), len(want))
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("frame %d: got %v, want %v", i, got[i], want[i])
+		}
+	}
+}
+
+func Test_ghostPos(t *testing.T) {
+	start := time.Date(2024, 10, 7, 13, 46, 47, 0, time.UTC)
+	ghost := []ghostFrame{
+		{at: 0, pos: 1},
+		{at: time.Second, pos: 2},
+		{at: 2 * time.Second, pos: 3},
+	}
+	testCases := []struct {
+		name      string
+		ghost     []ghostFrame
+		startTime time.Time
+		now       time.Time
+		want      int
+	}{
+		{
+			name:      "no ghost",
+			ghost:     nil,
+			startTime: start,
+			now:       start,
+			want:      -1,
+		},
+		{
+			name:      "ghost waits for the user to start",
+			ghost:     ghost,
+			startTime: time.Time{},
+			now:       start,
+			want:      0,
+		},
+		{
+			name:      "partway through the race",
+			ghost:     ghost,
+			startTime: start,
+			now:       start.Add(1500 * time.Millisecond),
+			want:      2,
+		},
+		{
+			name:      "ghost finished",
+			ghost:     ghost,
+			startTime: start,
+			now:       start.Add(time.Minute),
+			want:      3,
+		},
+	}
+
+	for _, tc := range testCases {
+		testModel := exerciseModel{
+			startTime:   tc.startTime,
+			ghost:       tc.ghost,
+			viewOptions: mockViewOptions,
+		}
+		got := testModel.ghostPos(tc.now)
+		if got != tc.want {
+			t.Errorf("%s: got %d, want %d", tc.name, got, tc.want)
+		}
+	}
+}
+
+func Test_renderLine_ghost(t *testing.T) {
+	oldProfile := lg.ColorProfile()
+	lg.SetColorProfile(termenv.TrueColor)
+	defer lg.SetColorProfile(oldProfile)
+
+	testStyles := styles{
+		untypedStyle: lg.NewStyle(),
+		cursorStyle:  lg.NewStyle(),
+		typedStyle:   lg.NewStyle(),
+		mistakeStyle: lg.NewStyle(),
+		ghostStyle:   lg.NewStyle().Foreground(lg.Color("1")),
+	}
+	typed := "as"
+	mistake := "aq"
+
+	testCases := []struct {
+		name   string
+		text   string
+		typed  *string
+		ghostI int
+		want   string
+	}{
+		{
+			name:   "ghost on an untyped line",
+			text:   "asdf",
+			typed:  nil,
+			ghostI: 2,
+			want:   "as" + util.Red("d") + "f",
+		},
+		{
+			name:   "ghost ahead of the user",
+			text:   "asdf",
+			typed:  &typed,
+			ghostI: 3,
+			want:   "asd" + util.Red("f"),
+		},
+		{
+			name:   "ghost behind the user",
+			text:   "asdf",
+			typed:  &typed,
+			ghostI: 0,
+			want:   util.Red("a") + "sdf",
+		},
+		{
+			name:   "mistakes are shown over the ghost",
+			text:   "asdf",
+			typed:  &mistake,
+			ghostI: 1,
+			want:   "asdf",
+		},
+	}
+
+	for _, tc := range testCases {
+		got := renderLine(tc.text, tc.typed, testStyles, false, false, tc.ghostI, nil)
+		if got != tc.want {
+			t.Errorf("%s\ngot\n%q\nwant\n%q", tc.name, got, tc.want)
+		}
+	}
+}
+
+func Test_Update_strict(t *testing.T) {
+	testModel := exerciseModel{
+		text:        "ab",
+		events:      []event.Event{},
+		strict:      true,
+		viewOptions: mockViewOptions,
+	}
+	keys := []struct {
+		key        rune
+		wantTyped  string
+		wantMissed bool
+	}{
+		{key: 'x', wantTyped: "", wantMissed: true},
+		{key: 'y', wantTyped: "", wantMissed: true},
+		{key: 'a', wantTyped: "a", wantMissed: false},
+	}
+
+	for _, k := range keys {
+		model, _ := testModel.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{k.key}})
+		testModel = model.(exerciseModel)
+		if testModel.typedText != k.wantTyped {
+			t.Errorf("after typing %q: got typed %q, want %q", k.key, testModel.typedText, k.wantTyped)
+		}
+		if testModel.missed != k.wantMissed {
+			t.Errorf("after typing %q: got missed %t, want %t", k.key, testModel.missed, k.wantMissed)
+		}
+	}
+
+	if got := len(testModel.events); got != 3 {
+		t.Errorf("wrong keys should still be recorded: got %d events, want 3", got)
+	}
+	if got := numMistakes(testModel.events); got != 2 {
+		t.Errorf("got %d mistakes, want 2", got)
+	}
+	if got := numUncorrectedErrors(testModel.events); got != 0 {
+		t.Errorf("got %d uncorrected errors, want 0", got)
+	}
+}
+
+func Test_Update_strictBackspace(t *testing.T) {
+	testModel := exerciseModel{
+		text:        "ab",
+		events:      []event.Event{},
+		strict:      true,
+		viewOptions: mockViewOptions,
+	}
+	keys := []struct {
+		msg        tea.KeyMsg
+		wantTyped  string
+		wantMissed bool
+	}{
+		{msg: tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'a'}}, wantTyped: "a", wantMissed: false},
+		{msg: tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'x'}}, wantTyped: "a", wantMissed: true},
+		// the wrong key was never typed, so there's nothing to delete
+		{msg: tea.KeyMsg{Type: tea.KeyBackspace}, wantTyped: "a", wantMissed: false},
+		{msg: tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'b'}}, wantTyped: "ab", wantMissed: false},
+	}
+
+	for _, k := range keys {
+		model, _ := testModel.Update(k.msg)
+		testModel = model.(exerciseModel)
+		if testModel.typedText != k.wantTyped {
+			t.Errorf("after typing %s: got typed %q, want %q", k.msg, testModel.typedText, k.wantTyped)
+		}
+		if testModel.missed != k.wantMissed {
+			t.Errorf("after typing %s: got missed %t, want %t", k.msg, testModel.missed, k.wantMissed)
+		}
+	}
+
+	// replaying the rep should end with the same text
+	replay := exerciseModel{text: "ab", strict: true, viewOptions: mockViewOptions}
+	for _, e := range testModel.events {
+		replay = replay.applyEvent(e)
+	}
+	if replay.typedText != "ab" {
+		t.Errorf("replay got typed %q, want %q", replay.typedText, "ab")
+	}
+}
+
+func Test_Update_multiByte(t *testing.T) {
+	testModel := exerciseModel{
+		text:        "λ→x",
+		events:      []event.Event{},
+		viewOptions: mockViewOptions,
+	}
+	keys := []struct {
+		key          rune
+		wantTyped    string
+		wantI        int
+		wantExpected string
+	}{
+		{key: 'λ', wantTyped: "λ", wantI: 0, wantExpected: "λ"},
+		{key: '→', wantTyped: "λ→", wantI: 1, wantExpected: "→"},
+		{key: 'y', wantTyped: "λ→y", wantI: 2, wantExpected: "x"},
+		// typing past the end of the exercise shouldn't panic
+		{key: 'z', wantTyped: "λ→y", wantI: 3, wantExpected: ""},
+	}
+
+	for _, k := range keys {
+		model, _ := testModel.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{k.key}})
+		testModel = model.(exerciseModel)
+		if testModel.typedText != k.wantTyped {
+			t.Errorf("after typing %q: got typed %q, want %q", k.key, testModel.typedText, k.wantTyped)
+		}
+		last := testModel.events[len(testModel.events)-1]
+		if last.I != k.wantI || last.Expected != k.wantExpected {
+			t.Errorf("after typing %q: got event %v, want index %d and expected %q", k.key, last, k.wantI, k.wantExpected)
+		}
+	}
+
+	// the view shouldn't panic once the user has reached the end
+	testModel.View()
+}
+
+func Test_applyEvent_strict(t *testing.T) {
+	events := event.ParseEvents(
+		"2024-10-07 13:46:47.000\t0\tx\ta\n" +
+			"2024-10-07 13:46:47.100\t0\ta\ta\n" +
+			"2024-10-07 13:46:47.200\t1\tb\tb",
+	)
+	testCases := []struct {
+		name   string
+		strict bool
+		want   string
+	}{
+		{
+			name:   "wrong keys are typed in normal reps",
+			strict: false,
+			want:   "xa",
+		},
+		{
+			name:   "wrong keys are skipped in strict reps",
+			strict: true,
+			want:   "ab",
+		},
+	}
+
+	for _, tc := range testCases {
+		testModel := exerciseModel{
+			text:        "ab",
+			strict:      tc.strict,
+			viewOptions: mockViewOptions,
+		}
+		for _, e := range events {
+			testModel = testModel.applyEvent(e)
+		}
+		if testModel.typedText != tc.want {
+			t.Errorf("%s: got %q, want %q", tc.name, testModel.typedText, tc.want)
+		}
+	}
+}
+
+func Test_renderText_strictMiss(t *testing.T) {
+	oldProfile := lg.ColorProfile()
+	lg.SetColorProfile(termenv.TrueColor)
+	defer lg.SetColorProfile(oldProfile)
+
+	testViewOptions := &viewOptions{
+		styles: styles{
+			untypedStyle: lg.NewStyle(),
+			cursorStyle:  lg.NewStyle(),
+			typedStyle:   lg.NewStyle(),
+			mistakeStyle: lg.NewStyle().Foreground(lg.Color("1")),
+		},
+	}
+	testModel := exerciseModel{
+		text:        "asdf",
+		typedText:   "as",
+		strict:      true,
+		missed:      true,
+		viewOptions: testViewOptions,
+	}
+	want := "as" + util.Red("d") + "f"
+	got := testModel.renderText()
+	if got != want {
+		t.Errorf("got\n%q\nwant\n%q", got, want)
+	}
+}
@@ -0,0 +1,101 @@
+package root
+
+import (
+	"unicode/utf8"
+
+	"github.com/NicksPatties/sweet/util"
+
+	"github.com/alecthomas/chroma/v2"
+	"github.com/alecthomas/chroma/v2/lexers"
+	lg "github.com/charmbracelet/lipgloss"
+)
+
+// The kinds of tokens that are highlighted in an exercise.
+type tokenClass int
+
+const (
+	plainToken tokenClass = iota
+	keywordToken
+	stringToken
+	commentToken
+	numberToken
+	punctuationToken
+)
+
+// Converts a chroma token type to the class it's highlighted with.
+func tokenTypeToClass(t chroma.TokenType) tokenClass {
+	switch {
+	case t.InCategory(chroma.Keyword):
+		return keywordToken
+	case t.InSubCategory(chroma.LiteralString):
+		return stringToken
+	case t.InCategory(chroma.Comment):
+		return commentToken
+	case t.InSubCategory(chroma.LiteralNumber):
+		return numberToken
+	case t.InCategory(chroma.Punctuation), t.InCategory(chroma.Operator):
+		return punctuationToken
+	default:
+		return plainToken
+	}
+}
+
+// Returns the token class of each rune of the text. The lexer is
+// chosen by the language of the exercise's name. If there's no
+// lexer for the language, then nil is returned, and the exercise
+// isn't highlighted.
+func tokenClasses(name string, text string) []tokenClass {
+	lang := util.Lang(name)
+	if lang == "" {
+		return nil
+	}
+	lexer := lexers.Get(lang)
+	if lexer == nil {
+		return nil
+	}
+	options := &chroma.TokeniseOptions{State: "root", EnsureLF: false}
+	iter, err := lexer.Tokenise(options, text)
+	if err != nil {
+		return nil
+	}
+
+	classes := make([]tokenClass, utf8.RuneCountInString(text))
+	i := 0
+	for _, token := range iter.Tokens() {
+		class := tokenTypeToClass(token.Type)
+		for range token.Value {
+			if i >= len(classes) {
+				break
+			}
+			classes[i] = class
+			i++
+		}
+	}
+	return classes
+}
+
+// Combines the style of a typed or untyped character with the style
+// of its token class. The token's colors are used, and the rest of
+// the base style is kept. Untyped tokens are faint, so the user can
+// still tell what they've typed.
+func (s styles) highlight(base lg.Style, class tokenClass, typed bool) lg.Style {
+	var classStyle lg.Style
+	switch class {
+	case keywordToken:
+		classStyle = s.keywordStyle
+	case stringToken:
+		classStyle = s.stringStyle
+	case commentToken:
+		classStyle = s.commentStyle
+	case numberToken:
+		classStyle = s.numberStyle
+	case punctuationToken:
+		classStyle = s.punctuationStyle
+	default:
+		return base
+	}
+	if !typed {
+		classStyle = classStyle.Faint(true)
+	}
+	return classStyle.Inherit(base)
+}
@@ -0,0 +1,105 @@
+package root
+
+import (
+	"testing"
+
+	lg "github.com/charmbracelet/lipgloss"
+	"github.com/muesli/termenv"
+)
+
+func Test_tokenClasses(t *testing.T) {
+	text := "func a() {\n\t// hi\n\treturn \"s\" + 1\n}\n"
+	got := tokenClasses("hello.go", text)
+	if len(got) != len(text) {
+		t.Fatalf("got %d classes, want %d", len(got), len(text))
+	}
+
+	want := map[int]tokenClass{
+		0:  keywordToken,     // func
+		5:  plainToken,       // a
+		6:  punctuationToken, // (
+		12: commentToken,     // //
+		19: keywordToken,     // return
+		26: stringToken,      // "
+		28: stringToken,      // "
+		30: punctuationToken, // +
+		32: numberToken,      // 1
+	}
+	for i, class := range want {
+		if got[i] != class {
+			t.Errorf("text[%d] = %q: got class %d, want %d", i, text[i], got[i], class)
+		}
+	}
+}
+
+func Test_tokenClasses_multiByte(t *testing.T) {
+	text := "// λ→\nreturn"
+	got := tokenClasses("hello.go", text)
+	if len(got) != len([]rune(text)) {
+		t.Fatalf("got %d classes, want one per rune (%d)", len(got), len([]rune(text)))
+	}
+	if got[3] != commentToken {
+		t.Errorf("λ: got class %d, want %d", got[3], commentToken)
+	}
+	if got[6] != keywordToken {
+		t.Errorf("return: got class %d, want %d", got[6], keywordToken)
+	}
+}
+
+func Test_tokenClasses_unknownLanguage(t *testing.T) {
+	for _, name := range []string{"notes", "notes.notalanguage"} {
+		if got := tokenClasses(name, "some text"); got != nil {
+			t.Errorf("%s: got %v, want nil", name, got)
+		}
+	}
+}
+
+func Test_renderLine_highlight(t *testing.T) {
+	oldProfile := lg.ColorProfile()
+	lg.SetColorProfile(termenv.TrueColor)
+	defer lg.SetColorProfile(oldProfile)
+
+	keyword := lg.NewStyle().Foreground(lg.Color("5"))
+	cursor := lg.NewStyle().Underline(true)
+	testStyles := styles{
+		untypedStyle: lg.NewStyle(),
+		cursorStyle:  cursor,
+		typedStyle:   lg.NewStyle(),
+		mistakeStyle: lg.NewStyle(),
+		keywordStyle: keyword,
+	}
+	text := "if x"
+	classes := []tokenClass{keywordToken, keywordToken, plainToken, plainToken}
+	typed := "i"
+
+	testCases := []struct {
+		name     string
+		typed    *string
+		vignette bool
+		want     string
+	}{
+		{
+			name:  "untyped keywords are faint",
+			typed: nil,
+			want:  keyword.Faint(true).Render("i") + keyword.Faint(true).Render("f") + " x",
+		},
+		{
+			name:  "typed keywords are bright, and the cursor isn't highlighted",
+			typed: &typed,
+			want:  keyword.Render("i") + cursor.Render("f") + " x",
+		},
+		{
+			name:     "vignette lines aren't highlighted",
+			typed:    nil,
+			vignette: true,
+			want:     "if x",
+		},
+	}
+
+	for _, tc := range testCases {
+		got := renderLine(text, tc.typed, testStyles, tc.vignette, tc.typed != nil, -1, classes)
+		if got != tc.want {
+			t.Errorf("%s\ngot\n%q\nwant\n%q", tc.name, got, tc.want)
+		}
+	}
+}
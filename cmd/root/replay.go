@@ -0,0 +1,265 @@
+package root
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"strconv"
+	"time"
+
+	"github.com/NicksPatties/sweet/config"
+	"github.com/NicksPatties/sweet/db"
+	"github.com/NicksPatties/sweet/event"
+	"github.com/NicksPatties/sweet/util"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/spf13/cobra"
+)
+
+// The time between each update of a replay.
+const replayTickInterval = 50 * time.Millisecond
+
+var replaySpeeds = []int{1, 2, 4}
+
+var replayCmd = &cobra.Command{
+	Use:   "replay [flags] rep-id",
+	Short: "Play back a saved rep",
+	Long: "Plays back the keystrokes of a saved rep with their original timing.\n" +
+		"While the replay is running, press space to pause or resume,\n" +
+		"1, 2, or 4 to change the speed, and q to quit.",
+	Example: "  replay rep 12 at double speed\n" +
+		"  sweet replay 12 --speed 2",
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		id, err := strconv.Atoi(args[0])
+		if err != nil {
+			return fmt.Errorf("invalid rep id %s", args[0])
+		}
+		speed, err := cmd.Flags().GetInt("speed")
+		if err != nil {
+			return err
+		}
+		if !validReplaySpeed(speed) {
+			return fmt.Errorf("invalid speed %d. speed must be one of %v", speed, replaySpeeds)
+		}
+		rep, text, err := repAndTextFromId(id)
+		if err != nil {
+			return err
+		}
+		viewOptions, err := viewOptionsFromArgs(cmd, text)
+		if err != nil {
+			return err
+		}
+		runReplay(*rep, text, speed, viewOptions)
+		return nil
+	},
+}
+
+func validReplaySpeed(speed int) bool {
+	for _, s := range replaySpeeds {
+		if s == speed {
+			return true
+		}
+	}
+	return false
+}
+
+// Gets a rep and the text of its exercise from the database.
+func repAndTextFromId(id int) (*db.Rep, string, error) {
+	statsDb, err := db.SweetDb()
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to connect to database: %s", err)
+	}
+	defer statsDb.Close()
+
+	rep, err := db.GetRep(statsDb, id)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to get rep %d: %s", id, err)
+	}
+	if rep == nil {
+		return nil, "", fmt.Errorf("rep %d not found", id)
+	}
+	if len(rep.Events) == 0 {
+		return nil, "", fmt.Errorf("rep %d has no keystrokes to replay", id)
+	}
+
+	text, err := db.GetExerciseText(statsDb, rep.Hash)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to get the text of rep %d: %s", id, err)
+	}
+	if text == "" {
+		text = exerciseTextFromDir(*rep)
+	}
+	if text == "" {
+		return nil, "", errors.New("the text of this rep's exercise wasn't saved, " +
+			"and it doesn't match any file in the exercises directory")
+	}
+	return rep, text, nil
+}
+
+// Finds the text of a rep's exercise in the exercises directory.
+// Reps saved before exercise texts were stored in the database
+// can still be replayed if their file hasn't changed since.
+func exerciseTextFromDir(rep db.Rep) string {
+	dir, err := config.ExercisesDir()
+	if err != nil {
+		return ""
+	}
+	text, err := os.ReadFile(path.Join(dir, rep.Name))
+	if err != nil || util.MD5Hash(string(text)) != rep.Hash {
+		return ""
+	}
+	return string(text)
+}
+
+// Sent periodically to move the replay forward.
+type replayTickMsg time.Time
+
+func replayTick() tea.Cmd {
+	return tea.Tick(replayTickInterval, func(t time.Time) tea.Msg {
+		return replayTickMsg(t)
+	})
+}
+
+// The replay model used by bubbletea.
+//
+// Implements tea.Model. Plays back the events of a rep
+// through an exercise model.
+type replayModel struct {
+	// The id of the rep being replayed.
+	id int
+
+	// The exercise that the events are applied to.
+	exercise exerciseModel
+
+	// The events of the rep being replayed.
+	events []event.Event
+
+	// The index of the next event to replay.
+	next int
+
+	// The time since the first event of the replay.
+	elapsed time.Duration
+
+	// How many times faster than the original rep
+	// the replay runs.
+	speed int
+
+	paused    bool
+	quitEarly bool
+}
+
+func newReplayModel(rep db.Rep, text string, speed int, options *viewOptions) replayModel {
+	exercise := exerciseModel{
+		name:        rep.Name,
+		text:        text,
+		events:      []event.Event{},
+		strict:      rep.Strict,
+		viewOptions: options,
+	}
+	if rep.Lim > 0 {
+		exercise.feed = text
+	}
+	if options.highlight {
+		exercise.classes = tokenClasses(rep.Name, text)
+	}
+	return replayModel{
+		id:       rep.Id,
+		exercise: exercise,
+		events:   event.RemovePauses(rep.Events, rep.Pauses),
+		speed:    speed,
+	}
+}
+
+// Moves the replay forward, and applies every event that
+// happened before the new elapsed time.
+func (m replayModel) advance(d time.Duration) replayModel {
+	m.elapsed += d
+	first := m.events[0].Ts
+	for m.next < len(m.events) && m.events[m.next].Ts.Sub(first) <= m.elapsed {
+		m.exercise = m.exercise.applyEvent(m.events[m.next])
+		m.next++
+	}
+	return m
+}
+
+func (m replayModel) done() bool {
+	return m.next >= len(m.events)
+}
+
+func (m replayModel) Init() tea.Cmd {
+	return replayTick()
+}
+
+func (m replayModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		switch msg.String() {
+		case "ctrl+c", "q":
+			m.quitEarly = true
+			return m, tea.Quit
+		case " ":
+			m.paused = !m.paused
+		case "1", "2", "4":
+			m.speed, _ = strconv.Atoi(msg.String())
+		}
+	case replayTickMsg:
+		if !m.paused {
+			m = m.advance(replayTickInterval * time.Duration(m.speed))
+		}
+		if m.done() {
+			return m, tea.Quit
+		}
+		return m, replayTick()
+	}
+	return m, nil
+}
+
+func (m replayModel) renderStatus() string {
+	state := fmt.Sprintf("%dx", m.speed)
+	if m.paused {
+		state = "paused"
+	}
+	status := fmt.Sprintf("// replay of rep %d  %s  %s  (space: pause, 1/2/4: speed, q: quit)",
+		m.id, formatClock(m.elapsed), state)
+	return m.exercise.viewOptions.styles.commentStyle.Render(status)
+}
+
+// Displays the exercise as it's being replayed.
+// Hides the view once the replay is complete or the user quits early.
+func (m replayModel) View() (s string) {
+	if m.done() || m.quitEarly {
+		return
+	}
+	s += m.exercise.View()
+	s += "\n\n"
+	s += m.renderStatus()
+	return
+}
+
+// Runs the replay. Once the replay is complete, prints the
+// results of the rep that was replayed.
+func runReplay(rep db.Rep, text string, speed int, options *viewOptions) {
+	teaModel, err := tea.NewProgram(newReplayModel(rep, text, speed, options)).Run()
+	if err != nil {
+		fmt.Printf("Error running replay: %v\n", err)
+		os.Exit(1)
+	}
+
+	replay, ok := teaModel.(replayModel)
+	if !ok {
+		fmt.Printf("Error casting bubbletea model.\n")
+	}
+	if replay.quitEarly {
+		os.Exit(0)
+	}
+
+	printExerciseResults(rep, options.layout)
+}
+
+func init() {
+	replayCmd.Flags().Int("speed", 1, fmt.Sprintf("replay speed, one of %v", replaySpeeds))
+	setViewFlags(replayCmd)
+	replayCmd.Flags().SortFlags = false
+}
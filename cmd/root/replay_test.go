@@ -0,0 +1,141 @@
+package root
+
+import (
+	"os"
+	"path"
+	"testing"
+	"time"
+
+	"github.com/NicksPatties/sweet/db"
+	"github.com/NicksPatties/sweet/event"
+	"github.com/NicksPatties/sweet/util"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+var replayTestRep = db.Rep{
+	Id:   1,
+	Name: "hey.txt",
+	Events: event.ParseEvents(
+		"2024-10-07 13:46:47.000\t0\th\th\n" +
+			"2024-10-07 13:46:47.100\t1\te\te\n" +
+			"2024-10-07 13:46:48.000\t2\ty\ty\n" +
+			"2024-10-07 13:46:49.000\t3\tenter\tenter",
+	),
+}
+
+func Test_replayModel_advance(t *testing.T) {
+	testCases := []struct {
+		name      string
+		advance   time.Duration
+		wantTyped string
+		wantDone  bool
+	}{
+		{
+			name:      "first event is replayed right away",
+			advance:   0,
+			wantTyped: "h",
+			wantDone:  false,
+		},
+		{
+			name:      "events up to the elapsed time are replayed",
+			advance:   time.Second,
+			wantTyped: "hey",
+			wantDone:  false,
+		},
+		{
+			name:      "all events replayed",
+			advance:   2 * time.Second,
+			wantTyped: "hey\n",
+			wantDone:  true,
+		},
+	}
+
+	for _, tc := range testCases {
+		m := newReplayModel(replayTestRep, "hey\n", 1, mockViewOptions)
+		m = m.advance(tc.advance)
+		if m.exercise.typedText != tc.wantTyped {
+			t.Errorf("%s: got typed %q, want %q", tc.name, m.exercise.typedText, tc.wantTyped)
+		}
+		if m.done() != tc.wantDone {
+			t.Errorf("%s: got done %t, want %t", tc.name, m.done(), tc.wantDone)
+		}
+	}
+}
+
+func Test_replayModel_Update(t *testing.T) {
+	m := newReplayModel(replayTestRep, "hey\n", 1, mockViewOptions)
+
+	model, _ := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'4'}})
+	m = model.(replayModel)
+	if m.speed != 4 {
+		t.Errorf("got speed %d, want 4", m.speed)
+	}
+
+	model, _ = m.Update(replayTickMsg(time.Now()))
+	m = model.(replayModel)
+	if m.elapsed != 4*replayTickInterval {
+		t.Errorf("got elapsed %s, want %s", m.elapsed, 4*replayTickInterval)
+	}
+
+	model, _ = m.Update(tea.KeyMsg{Type: tea.KeySpace, Runes: []rune{' '}})
+	m = model.(replayModel)
+	if !m.paused {
+		t.Fatal("replay should be paused")
+	}
+
+	model, _ = m.Update(replayTickMsg(time.Now()))
+	m = model.(replayModel)
+	if m.elapsed != 4*replayTickInterval {
+		t.Errorf("paused replay should not advance, got elapsed %s", m.elapsed)
+	}
+}
+
+func Test_validReplaySpeed(t *testing.T) {
+	for _, speed := range []int{1, 2, 4} {
+		if !validReplaySpeed(speed) {
+			t.Errorf("speed %d should be valid", speed)
+		}
+	}
+	for _, speed := range []int{0, 3, -1} {
+		if validReplaySpeed(speed) {
+			t.Errorf("speed %d should not be valid", speed)
+		}
+	}
+}
+
+func Test_exerciseTextFromDir(t *testing.T) {
+	tmpExercisesDir := t.TempDir()
+	t.Setenv("SWEET_EXERCISES_DIR", tmpExercisesDir)
+	text := "hey\n"
+	os.WriteFile(path.Join(tmpExercisesDir, "hey.txt"), []byte(text), 0600)
+
+	testCases := []struct {
+		name string
+		rep  db.Rep
+		want string
+	}{
+		{
+			name: "file matches the rep's hash",
+			rep:  db.Rep{Name: "hey.txt", Hash: util.MD5Hash(text)},
+			want: text,
+		},
+		{
+			name: "file changed since the rep",
+			rep:  db.Rep{Name: "hey.txt", Hash: util.MD5Hash("hello\n")},
+			want: "",
+		},
+		{
+			name: "file doesn't exist",
+			rep:  db.Rep{Name: "missing.txt", Hash: util.MD5Hash(text)},
+			want: "",
+		},
+	}
+
+	for _, tc := range testCases {
+		got := exerciseTextFromDir(tc.rep)
+		if got != tc.want {
+			t.Errorf("%s: got %q, want %q", tc.name, got, tc.want)
+		}
+	}
+}
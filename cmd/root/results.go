@@ -1,14 +1,17 @@
 package root
 
 import (
+	"database/sql"
 	"fmt"
 	"sort"
 	"strings"
 	"time"
 
+	"github.com/NicksPatties/sweet/cmd/stats"
 	"github.com/NicksPatties/sweet/constants"
 	db "github.com/NicksPatties/sweet/db"
 	"github.com/NicksPatties/sweet/event"
+	"github.com/NicksPatties/sweet/keyboard"
 	"github.com/NicksPatties/sweet/util"
 	g "github.com/guptarohit/asciigraph"
 )
@@ -224,18 +227,29 @@ func duration(events []event.Event) time.Duration {
 	return events[len(events)-1].Ts.Sub(events[0].Ts)
 }
 
-// Finds the most missed key presses when completing
-// an exercise. Missed keys are sorted alphabetically,
-// and by the number of misses. Also, sets a limit
-// of number of keys missed to avoid overflowing the line.
-func mostMissedKeys(events []event.Event) string {
+// Returns the total time spent paused during a rep.
+func pausedDuration(pauses event.Pauses) (d time.Duration) {
+	for _, p := range pauses {
+		d += p.End.Sub(p.Start)
+	}
+	return
+}
+
+// Counts the number of times each expected key was missed
+// in a series of events. Backspaces are not counted as misses.
+func missedKeys(events []event.Event) map[string]int {
 	misses := map[string]int{}
 	for _, e := range events {
 		if e.Typed != "backspace" && e.Typed != e.Expected {
 			misses[e.Expected]++
 		}
 	}
+	return misses
+}
 
+// Returns the keys of a map of misses, sorted by the number
+// of misses, and then alphabetically.
+func sortedMissedKeys(misses map[string]int) []string {
 	keys := []string{}
 	for key := range misses {
 		keys = append(keys, key)
@@ -246,6 +260,16 @@ func mostMissedKeys(events []event.Event) string {
 	sort.SliceStable(keys, func(i int, j int) bool {
 		return misses[keys[i]] > misses[keys[j]]
 	})
+	return keys
+}
+
+// Finds the most missed key presses when completing
+// an exercise. Missed keys are sorted alphabetically,
+// and by the number of misses. Also, sets a limit
+// of number of keys missed to avoid overflowing the line.
+func mostMissedKeys(events []event.Event) string {
+	misses := missedKeys(events)
+	keys := sortedMissedKeys(misses)
 
 	// A miss looks like this: "a (2 times)"
 	var missesStrs []string
@@ -264,17 +288,72 @@ func mostMissedKeys(events []event.Event) string {
 	return strings.Join(missesStrs, ", ")
 }
 
-// Prints the results of a repetition.
-func printExerciseResults(rep db.Rep) {
+// Prints the results of a repetition. The layout
+// chooses which fingers typed each key.
+func printExerciseResults(rep db.Rep, layout keyboard.Layout) {
 	fmt.Printf("results of %s:\n", rep.Name)
+	if rep.Lim > 0 {
+		fmt.Printf("time limit:          %s\n", rep.Lim)
+	}
 	fmt.Printf("wpm:                 %.f\n", rep.Wpm)
 	fmt.Printf("uncorrected errors:  %d\n", rep.Errs)
 	fmt.Printf("duration:            %s\n", rep.Dur)
+	if len(rep.Pauses) > 0 {
+		fmt.Printf("paused:              %s\n", pausedDuration(rep.Pauses).Round(time.Millisecond))
+	}
 	fmt.Printf("mistakes:            %d\n", rep.Miss)
 	fmt.Printf("accuracy:            %.2f%%\n", rep.Acc)
 	if rep.Miss > 0 {
 		fmt.Printf("most missed keys:    %s\n", mostMissedKeys(rep.Events))
 	}
-	fmt.Printf("graph:\n%s", wpmGraph(rep.Events))
+	fmt.Printf("graph:\n%s", wpmGraph(event.RemovePauses(rep.Events, rep.Pauses)))
 	fmt.Println()
+	if fingers := stats.RenderFingers([]db.Rep{rep}, layout); fingers != "" {
+		fmt.Printf("fingers:\n%s", fingers)
+	}
+}
+
+// Prints how far ahead or behind the ghost the user finished.
+func printGhostResults(rep db.Rep, ghost db.Rep) {
+	diff := (ghost.Dur - rep.Dur).Round(time.Millisecond)
+	switch {
+	case diff > 0:
+		fmt.Printf("ghost:               finished %s ahead of your best!\n", diff)
+	case diff < 0:
+		fmt.Printf("ghost:               finished %s behind your best\n", -diff)
+	default:
+		fmt.Printf("ghost:               tied with your best\n")
+	}
+}
+
+// Gets the hash of the latest rep of the exercise with the given
+// name, or an empty string if the exercise hasn't been typed before.
+func lastHash(statsDb *sql.DB, name string) (string, error) {
+	reps, err := db.GetReps(statsDb, db.RepFilter{
+		Name:  name,
+		Desc:  true,
+		Limit: 1,
+	})
+	if err != nil || len(reps) == 0 {
+		return "", err
+	}
+	return reps[0].Hash, nil
+}
+
+// Warns that the exercise's text changed since its last rep,
+// so its stats mix two versions unless they're filtered by hash.
+// Generated exercises change every rep, so they're never warned about.
+func printChangedWarning(rep db.Rep, last string) {
+	if rep.Generated() || last == "" || last == rep.Hash {
+		return
+	}
+	fmt.Printf("warning: %s has changed since your last rep of it. To see the stats of this version only, run:\n", rep.Name)
+	fmt.Printf("  sweet stats --name=%s --hash=%s\n", rep.Name, stats.ShortHash(rep.Hash))
+}
+
+// Prints the personal bests and streaks set by a rep.
+func printRecordResults(news []string) {
+	for _, n := range news {
+		fmt.Println(n)
+	}
 }
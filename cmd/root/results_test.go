@@ -2,10 +2,16 @@ package root
 
 import (
 	"fmt"
+	"math/rand"
+	"strings"
 	"testing"
 	"time"
 
+	"github.com/NicksPatties/sweet/cmd/stats"
+	consts "github.com/NicksPatties/sweet/constants"
+	"github.com/NicksPatties/sweet/db"
 	"github.com/NicksPatties/sweet/event"
+	"github.com/NicksPatties/sweet/util"
 )
 
 // mistakes:          3
@@ -347,6 +353,19 @@ func TestWpmRaw(t *testing.T) {
 
 }
 
+func TestMissedKeys(t *testing.T) {
+	got := missedKeys(event.ParseEvents(defaultCaseEventsList))
+	want := map[string]int{"h": 1, "y": 2}
+	if len(got) != len(want) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+	for key, count := range want {
+		if got[key] != count {
+			t.Errorf("key %s: got %d misses, want %d", key, got[key], count)
+		}
+	}
+}
+
 func TestMostMissedKeys(t *testing.T) {
 
 	type testCase struct {
@@ -488,3 +507,90 @@ func TestMostMissedKeys(t *testing.T) {
 		}
 	}
 }
+
+func TestChangedWarning(t *testing.T) {
+	t.Setenv("SWEET_DB_LOCATION", t.TempDir())
+	statsDb, err := db.SweetDb()
+	if err != nil {
+		t.Fatalf("failed to open database: %v", err)
+	}
+	defer statsDb.Close()
+
+	if last, err := lastHash(statsDb, "hello.go"); err != nil || last != "" {
+		t.Errorf("got hash %q and error %v for a new exercise, want neither", last, err)
+	}
+
+	start := time.Date(2024, 10, 7, 13, 46, 47, 0, time.UTC)
+	for i, hash := range []string{"1a2b3c4d5e", "f0e1d2c3b4"} {
+		rep := db.Rep{Hash: hash, Name: "hello.go", Start: start.Add(time.Duration(i) * time.Hour)}
+		if _, err := db.InsertRep(statsDb, rep); err != nil {
+			t.Fatalf("failed to insert rep: %v", err)
+		}
+	}
+	last, err := lastHash(statsDb, "hello.go")
+	if err != nil {
+		t.Fatalf("failed to get last hash: %v", err)
+	}
+	if last != "f0e1d2c3b4" {
+		t.Errorf("got last hash %q, want f0e1d2c3b4", last)
+	}
+
+	unchanged := util.GetStringFromStdout(func() {
+		printChangedWarning(db.Rep{Hash: "f0e1d2c3b4", Name: "hello.go"}, last)
+	})
+	if unchanged != "" {
+		t.Errorf("wanted no warning, got %q", unchanged)
+	}
+	changed := util.GetStringFromStdout(func() {
+		printChangedWarning(db.Rep{Hash: "1a2b3c4d5e", Name: "hello.go"}, last)
+	})
+	if !strings.Contains(changed, "sweet stats --name=hello.go --hash=1a2b3c4") {
+		t.Errorf("wanted a warning with the new hash, got %q", changed)
+	}
+}
+
+func TestChangedWarningDrills(t *testing.T) {
+	t.Setenv("SWEET_DB_LOCATION", t.TempDir())
+	statsDb, err := db.SweetDb()
+	if err != nil {
+		t.Fatalf("failed to open database: %v", err)
+	}
+	defer statsDb.Close()
+
+	misses := map[string]int{"q": 3, "z": 1}
+	start := time.Date(2024, 10, 7, 13, 46, 47, 0, time.UTC)
+	reps := []db.Rep{}
+	for i := range 2 {
+		text := generateDrill(misses, 3, rand.New(rand.NewSource(int64(i))))
+		rep := db.Rep{
+			Hash:  util.MD5Hash(text),
+			Name:  consts.DRILL_NAME,
+			Start: start.Add(time.Duration(i) * time.Hour),
+		}
+		last, err := lastHash(statsDb, rep.Name)
+		if err != nil {
+			t.Fatalf("failed to get last hash: %v", err)
+		}
+		warning := util.GetStringFromStdout(func() {
+			printChangedWarning(rep, last)
+		})
+		if warning != "" {
+			t.Errorf("drill %d: wanted no warning, got %q", i, warning)
+		}
+		if _, err := db.InsertRep(statsDb, rep); err != nil {
+			t.Fatalf("failed to insert rep: %v", err)
+		}
+		reps = append(reps, rep)
+	}
+	if reps[0].Hash == reps[1].Hash {
+		t.Fatalf("wanted drills with different text, got the same hash %s", reps[0].Hash)
+	}
+
+	labels := map[string]bool{}
+	for _, label := range stats.ExerciseLabels(reps) {
+		labels[label] = true
+	}
+	if len(labels) != 1 || !labels[consts.DRILL_NAME] {
+		t.Errorf("wanted only the %q label, got %v", consts.DRILL_NAME, labels)
+	}
+}
@@ -0,0 +1,245 @@
+package root
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"os"
+	"path"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	db "github.com/NicksPatties/sweet/db"
+)
+
+// The ways the next random exercise can be chosen.
+const (
+	// Any exercise, with the same chance as the others.
+	scheduleRandom = "random"
+
+	// The exercise that's due for practice the soonest,
+	// based on how well its previous reps went.
+	scheduleSRS = "srs"
+
+	// Any exercise, but the ones with more of the keys
+	// you miss are more likely to be chosen.
+	scheduleWeak = "weak"
+)
+
+var schedules = []string{scheduleRandom, scheduleSRS, scheduleWeak}
+
+// How much more likely the exercise with the most missed keys is
+// to be chosen by the weak schedule than an exercise without any.
+const weakMissWeight = 10.0
+
+// Chooses which of the exercises with the given file names is next,
+// and returns its index.
+type scheduler func(names []string) int
+
+// Returns the scheduler for a schedule. Schedules that use the
+// reps of the exercises get them from the database. The exercises
+// are files in dir. If language isn't empty, then only the reps of
+// that language are used.
+func newScheduler(schedule string, dir string, language string, random *rand.Rand) (scheduler, error) {
+	switch schedule {
+	case scheduleRandom:
+		return func(names []string) int {
+			return random.Intn(len(names))
+		}, nil
+	case scheduleSRS:
+		reps, err := allReps()
+		if err != nil {
+			return nil, err
+		}
+		return func(names []string) int {
+			return srsPick(names, reps, random)
+		}, nil
+	case scheduleWeak:
+		filter := db.RepFilter{}
+		if language != "" {
+			filter.Langs = []string{language}
+		}
+		misses, err := missedKeysOf(filter)
+		if err != nil {
+			return nil, err
+		}
+		return func(names []string) int {
+			texts := make([]string, len(names))
+			for i, name := range names {
+				// unreadable files are left to the caller
+				text, _ := os.ReadFile(path.Join(dir, name))
+				texts[i] = string(text)
+			}
+			return weakPick(texts, misses, random)
+		}, nil
+	default:
+		return nil, fmt.Errorf("unknown schedule %q, must be one of: %s", schedule, strings.Join(schedules, ", "))
+	}
+}
+
+// Gets every rep, sorted by when they started.
+func allReps() ([]db.Rep, error) {
+	statsDb, err := db.SweetDb()
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to database: %s", err)
+	}
+	defer statsDb.Close()
+	return db.GetReps(statsDb, db.RepFilter{})
+}
+
+// How well an exercise is remembered, following the SM-2
+// spaced repetition algorithm. Each rep is a review.
+type srsCard struct {
+	// How easy the exercise is. The easier it is, the
+	// faster its interval grows.
+	ease float64
+
+	// Days until the exercise should be practiced again.
+	interval int
+
+	// Number of good reps in a row.
+	streak int
+
+	// When the exercise should be practiced again.
+	due time.Time
+}
+
+// The ease of an exercise that hasn't been practiced.
+const srsStartingEase = 2.5
+
+// The ease never drops below this, so hard exercises still
+// get longer intervals as they're practiced.
+const srsMinEase = 1.3
+
+// Grades a rep from 0 to 5, like a review in SM-2. The grade comes
+// from the rep's accuracy, and goes up or down a point if its wpm
+// is 10% faster or slower than the average of the earlier reps.
+// A grade of 3 or more is a good rep.
+func srsGrade(rep db.Rep, prevAvgWpm float64) int {
+	var grade int
+	switch {
+	case rep.Acc >= 98:
+		grade = 5
+	case rep.Acc >= 95:
+		grade = 4
+	case rep.Acc >= 90:
+		grade = 3
+	case rep.Acc >= 80:
+		grade = 2
+	case rep.Acc >= 70:
+		grade = 1
+	}
+	if prevAvgWpm > 0 {
+		switch {
+		case rep.Wpm >= prevAvgWpm*1.1:
+			grade++
+		case rep.Wpm <= prevAvgWpm*0.9:
+			grade--
+		}
+	}
+	return min(max(grade, 0), 5)
+}
+
+// Reviews an exercise with each of its reps, which must be
+// sorted by when they started. Several reps on the same day
+// only count as one review, graded by the day's last rep.
+func srsCardOf(reps []db.Rep) srsCard {
+	card := srsCard{ease: srsStartingEase}
+	wpmSum := 0.0
+	for i, rep := range reps {
+		prevAvgWpm := 0.0
+		if i > 0 {
+			prevAvgWpm = wpmSum / float64(i)
+		}
+		wpmSum += rep.Wpm
+		if i+1 < len(reps) && sameDay(rep.Start, reps[i+1].Start) {
+			continue
+		}
+
+		grade := srsGrade(rep, prevAvgWpm)
+		if grade >= 3 {
+			switch card.streak {
+			case 0:
+				card.interval = 1
+			case 1:
+				card.interval = 6
+			default:
+				card.interval = int(math.Round(float64(card.interval) * card.ease))
+			}
+			card.streak++
+		} else {
+			card.streak = 0
+			card.interval = 1
+		}
+		miss := float64(5 - grade)
+		card.ease = max(card.ease+0.1-miss*(0.08+miss*0.02), srsMinEase)
+		card.due = rep.Start.AddDate(0, 0, card.interval)
+	}
+	return card
+}
+
+func sameDay(a time.Time, b time.Time) bool {
+	a, b = a.Local(), b.Local()
+	return a.Year() == b.Year() && a.YearDay() == b.YearDay()
+}
+
+// Picks the exercise that's due the soonest, or the most overdue.
+// Exercises that were never practiced are picked before any other.
+// Ties are broken randomly. Exercises are matched to their reps
+// by their file names, so edited files keep their history.
+func srsPick(names []string, reps []db.Rep, random *rand.Rand) int {
+	byName := map[string][]db.Rep{}
+	for _, rep := range reps {
+		byName[rep.Name] = append(byName[rep.Name], rep)
+	}
+
+	picks := []int{}
+	var soonest time.Time
+	for i, name := range names {
+		// never practiced, so it's due right away
+		due := time.Time{}
+		if reps := byName[name]; len(reps) > 0 {
+			due = srsCardOf(reps).due
+		}
+		switch {
+		case len(picks) == 0 || due.Before(soonest):
+			picks = []int{i}
+			soonest = due
+		case due.Equal(soonest):
+			picks = append(picks, i)
+		}
+	}
+	return picks[random.Intn(len(picks))]
+}
+
+// Returns how heavily a text is made of missed keys: the average
+// share of the total misses of each of its runes.
+func missDensity(text string, misses map[string]int) float64 {
+	runes := utf8.RuneCountInString(text)
+	if runes == 0 {
+		return 0
+	}
+	return missShare(text, misses) / float64(runes)
+}
+
+// Picks one of the texts at random, weighted by their miss density.
+// The text with the densest misses is weakMissWeight times more
+// likely to be picked than a text without any, so every text can
+// still be picked.
+func weakPick(texts []string, misses map[string]int, random *rand.Rand) int {
+	densities := make([]float64, len(texts))
+	densest := 0.0
+	for i, text := range texts {
+		densities[i] = missDensity(text, misses)
+		densest = max(densest, densities[i])
+	}
+	weights := make([]float64, len(texts))
+	for i, d := range densities {
+		weights[i] = 1
+		if densest > 0 {
+			weights[i] += (weakMissWeight - 1) * d / densest
+		}
+	}
+	return weightedPick(weights, random)
+}
@@ -0,0 +1,148 @@
+package root
+
+import (
+	"math/rand"
+	"testing"
+	"time"
+
+	db "github.com/NicksPatties/sweet/db"
+)
+
+func TestSrsGrade(t *testing.T) {
+	testCases := []struct {
+		name       string
+		acc        float64
+		wpm        float64
+		prevAvgWpm float64
+		want       int
+	}{
+		{"perfect first rep", 100, 50, 0, 5},
+		{"accurate and faster", 96, 60, 50, 5},
+		{"accurate but slower", 96, 40, 50, 3},
+		{"sloppy", 85, 50, 50, 2},
+		{"sloppy and slower", 60, 40, 50, 0},
+		{"can't go past 5", 100, 80, 50, 5},
+	}
+	for _, tc := range testCases {
+		got := srsGrade(db.Rep{Acc: tc.acc, Wpm: tc.wpm}, tc.prevAvgWpm)
+		if got != tc.want {
+			t.Errorf("%s: got %d, want %d", tc.name, got, tc.want)
+		}
+	}
+}
+
+func TestSrsCardOf(t *testing.T) {
+	day := func(d int) time.Time {
+		return time.Date(2024, 10, d, 12, 0, 0, 0, time.Local)
+	}
+	good := func(d int) db.Rep {
+		return db.Rep{Start: day(d), Acc: 98, Wpm: 50}
+	}
+
+	// intervals grow from 1 day to 6 days, then by the ease
+	card := srsCardOf([]db.Rep{good(1), good(2), good(8)})
+	if card.streak != 3 || card.interval != 16 {
+		t.Errorf("got a streak of %d and an interval of %d days, want 3 and 16", card.streak, card.interval)
+	}
+	if !card.due.Equal(day(24)) {
+		t.Errorf("got due %s, want %s", card.due, day(24))
+	}
+
+	// a bad rep starts over, and makes the exercise harder
+	bad := db.Rep{Start: day(9), Acc: 50, Wpm: 50}
+	card = srsCardOf([]db.Rep{good(1), good(2), good(8), bad})
+	if card.streak != 0 || card.interval != 1 || card.ease >= srsStartingEase {
+		t.Errorf("got %+v, want a reset streak and a lower ease", card)
+	}
+
+	// only the last rep of a day is a review
+	sameDay := good(1)
+	sameDay.Start = sameDay.Start.Add(time.Hour)
+	card = srsCardOf([]db.Rep{good(1), sameDay})
+	if card.streak != 1 {
+		t.Errorf("got a streak of %d, want 1", card.streak)
+	}
+}
+
+func TestSrsPick(t *testing.T) {
+	day := func(d int) time.Time {
+		return time.Date(2024, 10, d, 12, 0, 0, 0, time.Local)
+	}
+	reps := []db.Rep{
+		{Name: "easy.go", Start: day(1), Acc: 100, Wpm: 60},
+		{Name: "easy.go", Start: day(2), Acc: 100, Wpm: 60},
+		{Name: "hard.go", Start: day(2), Acc: 60, Wpm: 30},
+	}
+	random := rand.New(rand.NewSource(1))
+
+	names := []string{"easy.go", "hard.go"}
+	if got := names[srsPick(names, reps, random)]; got != "hard.go" {
+		t.Errorf("got %s, want the exercise that's due first", got)
+	}
+
+	names = []string{"easy.go", "hard.go", "new.go"}
+	if got := names[srsPick(names, reps, random)]; got != "new.go" {
+		t.Errorf("got %s, want the exercise that was never practiced", got)
+	}
+}
+
+func TestNewScheduler(t *testing.T) {
+	random := rand.New(rand.NewSource(1))
+	next, err := newScheduler(scheduleRandom, t.TempDir(), "", random)
+	if err != nil {
+		t.Fatalf("failed to create scheduler: %v", err)
+	}
+	if i := next([]string{"a.go", "b.go"}); i < 0 || i > 1 {
+		t.Errorf("got index %d out of range", i)
+	}
+	if _, err := newScheduler("alphabetical", t.TempDir(), "", random); err == nil {
+		t.Errorf("wanted error for an unknown schedule, got nil")
+	}
+}
+
+func TestMissDensity(t *testing.T) {
+	misses := map[string]int{"{": 3, "}": 1}
+	testCases := []struct {
+		text string
+		want float64
+	}{
+		{"", 0},
+		{"abc", 0},
+		{"{}", 0.5},
+		{"{{", 0.75},
+		{"{ab}", 0.25},
+	}
+	for _, tc := range testCases {
+		if got := missDensity(tc.text, misses); got != tc.want {
+			t.Errorf("%q: got %f, want %f", tc.text, got, tc.want)
+		}
+	}
+}
+
+func TestWeakPick(t *testing.T) {
+	misses := map[string]int{"{": 3, "}": 1}
+	texts := []string{"plain text\n", "if x {\n}\n", "{{}}\n"}
+	random := rand.New(rand.NewSource(1))
+
+	picks := make([]int, len(texts))
+	for i := 0; i < 3000; i++ {
+		picks[weakPick(texts, misses, random)]++
+	}
+	if picks[0] == 0 {
+		t.Errorf("texts without missed keys should still be picked sometimes")
+	}
+	if !(picks[0] < picks[1] && picks[1] < picks[2]) {
+		t.Errorf("got picks %v, want texts with denser misses picked more often", picks)
+	}
+
+	// without any misses, every text is as likely as the others
+	picks = make([]int, len(texts))
+	for i := 0; i < 3000; i++ {
+		picks[weakPick(texts, map[string]int{}, random)]++
+	}
+	for i, p := range picks {
+		if p < 800 || p > 1200 {
+			t.Errorf("text %d was picked %d times out of 3000, want about 1000", i, p)
+		}
+	}
+}
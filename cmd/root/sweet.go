@@ -12,11 +12,18 @@ import (
 	"os"
 	"path"
 	"strings"
+	"time"
 
 	"github.com/NicksPatties/sweet/cmd/about"
 	"github.com/NicksPatties/sweet/cmd/add"
+	configcmd "github.com/NicksPatties/sweet/cmd/config"
+	"github.com/NicksPatties/sweet/cmd/export"
+	"github.com/NicksPatties/sweet/cmd/importer"
+	"github.com/NicksPatties/sweet/cmd/records"
 	"github.com/NicksPatties/sweet/cmd/stats"
 	"github.com/NicksPatties/sweet/cmd/version"
+	"github.com/NicksPatties/sweet/config"
+	"github.com/NicksPatties/sweet/keyboard"
 	"github.com/NicksPatties/sweet/util"
 
 	lg "github.com/charmbracelet/lipgloss"
@@ -38,6 +45,35 @@ type viewOptions struct {
 	// in the exericse. Default value is 0, meaning
 	// show the entire exercise.
 	windowSize uint
+
+	// If true, color the exercise's text by the syntax
+	// of its language.
+	highlight bool
+
+	// The keyboard layout that shows which keys and
+	// fingers to use.
+	layout keyboard.Layout
+}
+
+// Controls the behavior of the exercise performed
+// with this command.
+type exerciseOptions struct {
+	// The length of a timed exercise. Default value is 0,
+	// meaning the exercise ends once all of its text is typed.
+	timeLimit time.Duration
+
+	// If true, race against the fastest previous rep
+	// of the exercise.
+	ghost bool
+
+	// If true, mistakes must be corrected before moving
+	// on to the next character.
+	strict bool
+
+	// How long the user can go without typing before the
+	// exercise pauses itself. If 0, the exercise never pauses
+	// unless the user pauses it.
+	idleTimeout time.Duration
 }
 
 type styles struct {
@@ -47,19 +83,60 @@ type styles struct {
 	typedStyle    lg.Style
 	mistakeStyle  lg.Style
 	vignetteStyle lg.Style
+	ghostStyle    lg.Style
+
+	// Syntax highlighting styles. Comments use the commentStyle.
+	keywordStyle     lg.Style
+	stringStyle      lg.Style
+	numberStyle      lg.Style
+	punctuationStyle lg.Style
+
+	// Highlights the keys and fingers used to type the next character.
+	keyboardStyle lg.Style
 }
 
-func defaultStyles() styles {
+// Creates the styles for the colors of a theme.
+func themeStyles(theme config.Theme) styles {
+	style := func(name string) lg.Style {
+		s := lg.NewStyle()
+		if fg := theme[name].Foreground; fg != "" {
+			s = s.Foreground(lg.Color(fg))
+		}
+		if bg := theme[name].Background; bg != "" {
+			s = s.Background(lg.Color(bg))
+		}
+		return s
+	}
+
+	// Keys without colors are reversed, so they
+	// stand out with any terminal colors.
+	keyboardStyle := style("keyboard").Bold(true)
+	if theme["keyboard"] == (config.Style{}) {
+		keyboardStyle = keyboardStyle.Reverse(true)
+	}
+
 	return styles{
-		commentStyle:  lg.NewStyle().Foreground(lg.Color("7")).Italic(true),
-		untypedStyle:  lg.NewStyle().Foreground(lg.Color("7")),
-		cursorStyle:   lg.NewStyle().Background(lg.Color("15")).Foreground(lg.Color("0")),
-		typedStyle:    lg.NewStyle().Foreground(lg.Color("15")),
-		mistakeStyle:  lg.NewStyle().Background(lg.Color("1")).Foreground(lg.Color("15")),
-		vignetteStyle: lg.NewStyle().Foreground(lg.Color("8")),
+		commentStyle:  style("comment").Italic(true),
+		untypedStyle:  style("untyped"),
+		cursorStyle:   style("cursor"),
+		typedStyle:    style("typed"),
+		mistakeStyle:  style("mistake"),
+		vignetteStyle: style("vignette"),
+		ghostStyle:    style("ghost"),
+
+		keywordStyle:     style("keyword"),
+		stringStyle:      style("string"),
+		numberStyle:      style("number"),
+		punctuationStyle: style("punctuation"),
+
+		keyboardStyle: keyboardStyle,
 	}
 }
 
+func defaultStyles() styles {
+	return themeStyles(config.Themes[config.DefaultTheme])
+}
+
 var Cmd = &cobra.Command{
 	Use:     "sweet [file]",
 	Long:    fmt.Sprintf("%s.\nRuns an interactive touch typing game, and prints the results.", tagline()),
@@ -74,7 +151,11 @@ var Cmd = &cobra.Command{
 		if err != nil {
 			return err
 		}
-		run(exercise.name, exercise.text, viewOptions)
+		exerciseOptions, err := exerciseOptionsFromArgs(cmd)
+		if err != nil {
+			return err
+		}
+		run(exercise.name, exercise.text, viewOptions, exerciseOptions)
 		return nil
 	},
 }
@@ -95,8 +176,16 @@ func tagline() string {
 func examples() (msg string) {
 	msg += fmt.Sprintf("  Run a random exercise\n")
 	msg += fmt.Sprintf("  $ sweet\n\n")
+	msg += fmt.Sprintf("  Run the exercise that's due for practice, based on your previous reps\n")
+	msg += fmt.Sprintf("  $ sweet --schedule srs\n\n")
+	msg += fmt.Sprintf("  Run a random Go exercise, favoring the ones with the keys you miss\n")
+	msg += fmt.Sprintf("  $ sweet --schedule weak -l go\n\n")
 	msg += fmt.Sprintf("  Run an exercise from lines 2 to 10 of a file\n")
 	msg += fmt.Sprintf("  $ sweet file -s 2 -e 10\n\n")
+	msg += fmt.Sprintf("  Type for one minute, no matter how long the exercise is\n")
+	msg += fmt.Sprintf("  $ sweet --time 60s\n\n")
+	msg += fmt.Sprintf("  Race against your fastest rep of a file\n")
+	msg += fmt.Sprintf("  $ sweet file --ghost\n\n")
 	msg += fmt.Sprintf("  Run an exercise with STDIN (use `-` as your file)\n")
 	msg += fmt.Sprintf("  $ curl https://nickspatties.com/main.go | sweet -")
 	return
@@ -113,6 +202,11 @@ func exerciseFileFromArgs(cmd *cobra.Command, args []string) (exercise exerciseF
 		return
 	}
 
+	if len(args) > 0 && cmd.Flags().Changed("schedule") {
+		err = errors.New("schedule should not be assigned for a specific file")
+		return
+	}
+
 	var file *os.File
 	var text string
 	defer file.Close()
@@ -139,16 +233,9 @@ func exerciseFileFromArgs(cmd *cobra.Command, args []string) (exercise exerciseF
 		}
 
 		var exercisesDir string
-		if envDir := os.Getenv("SWEET_EXERCISES_DIR"); envDir != "" {
-			exercisesDir = envDir
-
-		} else {
-			var sweetConfigDir string
-			sweetConfigDir, err = util.SweetConfigDir()
-			if err != nil {
-				return
-			}
-			exercisesDir = path.Join(sweetConfigDir, "exercises")
+		exercisesDir, err = config.ExercisesDir()
+		if err != nil {
+			return
 		}
 
 		if err = os.MkdirAll(exercisesDir, 0775); err != nil {
@@ -186,9 +273,21 @@ func exerciseFileFromArgs(cmd *cobra.Command, args []string) (exercise exerciseF
 			files = addDefaultExercises(exercisesDir)
 			numFiles = len(files)
 		}
+
+		schedule, _ := cmd.Flags().GetString("schedule")
+		var next scheduler
+		next, err = newScheduler(schedule, exercisesDir, language, rand.New(rand.NewSource(time.Now().UnixNano())))
+		if err != nil {
+			return
+		}
+
 		// finding a valid exercise file
 		for text == "" {
-			randI := rand.Intn(numFiles)
+			names := []string{}
+			for _, f := range files {
+				names = append(names, f.Name())
+			}
+			randI := next(names)
 			filePath := path.Join(exercisesDir, files[randI].Name())
 			file, err = os.Open(filePath)
 			if err != nil {
@@ -293,17 +392,86 @@ func addDefaultExercises(dir string) (files []os.DirEntry) {
 }
 
 func viewOptionsFromArgs(cmd *cobra.Command, exerciseText string) (*viewOptions, error) {
+	conf, err := config.Load()
+	if err != nil {
+		return nil, err
+	}
 	windowSize, err := cmd.Flags().GetUint("window-size")
 	if err != nil {
 		return nil, err
 	}
+	if !cmd.Flags().Changed("window-size") && conf.WindowSize > 0 {
+		windowSize = conf.WindowSize
+	}
 	numLines := uint(len(util.Lines(exerciseText)))
 	if windowSize >= numLines {
 		windowSize = 0
 	}
+	noHighlight, err := cmd.Flags().GetBool("no-highlight")
+	if err != nil {
+		return nil, err
+	}
+	layoutName, err := cmd.Flags().GetString("layout")
+	if err != nil {
+		return nil, err
+	}
+	if !cmd.Flags().Changed("layout") && conf.Layout != "" {
+		layoutName = conf.Layout
+	}
+	layout, err := keyboard.Get(layoutName)
+	if err != nil {
+		return nil, err
+	}
+	themeName, err := cmd.Flags().GetString("theme")
+	if err != nil {
+		return nil, err
+	}
+	if cmd.Flags().Changed("theme") {
+		conf.ThemeName = themeName
+	}
+	theme, err := conf.Theme()
+	if err != nil {
+		return nil, err
+	}
 	return &viewOptions{
-		styles:     defaultStyles(),
+		styles:     themeStyles(theme),
 		windowSize: windowSize,
+		highlight:  !noHighlight,
+		layout:     layout,
+	}, nil
+}
+
+func exerciseOptionsFromArgs(cmd *cobra.Command) (*exerciseOptions, error) {
+	timeLimit, err := cmd.Flags().GetDuration("time")
+	if err != nil {
+		return nil, err
+	}
+	if timeLimit < 0 {
+		return nil, fmt.Errorf("time flag %s cannot be negative", timeLimit)
+	}
+	ghost, err := cmd.Flags().GetBool("ghost")
+	if err != nil {
+		return nil, err
+	}
+	if ghost && timeLimit > 0 {
+		return nil, errors.New("cannot race a ghost in a timed exercise")
+	}
+	strict, err := cmd.Flags().GetBool("strict")
+	if err != nil {
+		return nil, err
+	}
+	idleTimeout, err := cmd.Flags().GetDuration("idle")
+	if err != nil {
+		return nil, err
+	}
+	if idleTimeout < 0 {
+		return nil, fmt.Errorf("idle flag %s cannot be negative", idleTimeout)
+	}
+	return &exerciseOptions{
+		timeLimit:   timeLimit,
+		ghost:       ghost,
+		strict:      strict,
+		idleTimeout: idleTimeout,
 	}, nil
 }
 
@@ -314,6 +482,12 @@ func init() {
 	commands := []*cobra.Command{
 		about.Cmd,
 		add.Cmd,
+		configcmd.Cmd,
+		drillCmd,
+		export.Cmd,
+		importer.Cmd,
+		records.Cmd,
+		replayCmd,
 		version.Cmd,
 		stats.Cmd,
 	}
@@ -327,6 +501,32 @@ func setRootCmdFlags(cmd *cobra.Command) {
 	cmd.Flags().StringP("language", "l", "", "select a language by file extension")
 	cmd.Flags().UintP("start", "s", 1, "start exercise at this line")
 	cmd.Flags().UintP("end", "e", math.MaxUint, "end exercise at this line")
-	cmd.Flags().UintP("window-size", "w", 0, "set the number of visible lines for the exercise")
+	cmd.Flags().String("schedule", scheduleRandom,
+		fmt.Sprintf("how to choose a random exercise (%s)", strings.Join(schedules, ", ")))
+	setExerciseFlags(cmd)
 	cmd.Flags().SortFlags = false
 }
+
+// Sets the flags that control how an exercise looks. These are
+// shared by every command that shows an exercise.
+func setViewFlags(cmd *cobra.Command) {
+	cmd.Flags().UintP("window-size", "w", 0, "set the number of visible lines for the exercise")
+	cmd.Flags().Bool("no-highlight", false, "don't color the exercise by the syntax of its language")
+	cmd.Flags().String("layout", keyboard.DefaultLayout,
+		fmt.Sprintf("keyboard layout to show while typing (%s)", strings.Join(keyboard.Names(), ", ")))
+	cmd.Flags().String("theme", config.DefaultTheme,
+		fmt.Sprintf("color theme, either your own or a built-in one (%s)", strings.Join(config.Config{}.ThemeNames(), ", ")))
+}
+
+// The default time without typing before an exercise pauses itself.
+const defaultIdleTimeout = 30 * time.Second
+
+// Sets the flags that control how an exercise runs. These are
+// shared by every command that runs an exercise.
+func setExerciseFlags(cmd *cobra.Command) {
+	setViewFlags(cmd)
+	cmd.Flags().DurationP("time", "t", 0, "end the exercise after this amount of time, i.e. 60s")
+	cmd.Flags().BoolP("ghost", "g", false, "race against your fastest previous rep of the exercise")
+	cmd.Flags().Bool("strict", false, "correct each mistake before moving on to the next character")
+	cmd.Flags().Duration("idle", defaultIdleTimeout, "pause the exercise after this long without typing, 0 to never pause")
+}
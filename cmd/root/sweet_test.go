@@ -8,8 +8,13 @@ import (
 	"os"
 	"path"
 	"testing"
+	"time"
 
+	"github.com/NicksPatties/sweet/config"
+	"github.com/NicksPatties/sweet/db"
 	"github.com/NicksPatties/sweet/util"
+
+	lg "github.com/charmbracelet/lipgloss"
 	"github.com/spf13/cobra"
 )
 
@@ -493,6 +498,9 @@ var mockViewOptionsCmd = func(tc fromArgsViewOptionsTestCase) *cobra.Command {
 }
 
 func Test_viewOptionsFromArgs(t *testing.T) {
+	// don't read the user's config file
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+
 	testCases := []fromArgsViewOptionsTestCase{
 		{
 			args:         []string{""},
@@ -548,6 +556,42 @@ func Test_viewOptionsFromArgs(t *testing.T) {
 				}
 			},
 		},
+		{
+			args:         []string{""},
+			exerciseText: "an exercise",
+			check: func(got *viewOptions, gotErr error) {
+				name := "default layout should be qwerty"
+				if gotErr != nil {
+					t.Fatalf("%s wanted no error, got %s\n", name, gotErr)
+				}
+				if got.layout.Name != "qwerty" {
+					t.Fatalf("%s got layout %s", name, got.layout.Name)
+				}
+			},
+		},
+		{
+			args:         []string{"--layout", "dvorak"},
+			exerciseText: "an exercise",
+			check: func(got *viewOptions, gotErr error) {
+				name := "passing the dvorak layout"
+				if gotErr != nil {
+					t.Fatalf("%s wanted no error, got %s\n", name, gotErr)
+				}
+				if got.layout.Name != "dvorak" {
+					t.Fatalf("%s got layout %s", name, got.layout.Name)
+				}
+			},
+		},
+		{
+			args:         []string{"--layout", "azerty"},
+			exerciseText: "an exercise",
+			check: func(got *viewOptions, gotErr error) {
+				name := "unknown layout"
+				if gotErr == nil {
+					t.Fatalf("%s wanted error, got nil\n", name)
+				}
+			},
+		},
 	}
 
 	for _, tc := range testCases {
@@ -558,6 +602,233 @@ func Test_viewOptionsFromArgs(t *testing.T) {
 	}
 }
 
+func Test_viewOptionsFromArgs_withConfig(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	conf := config.Config{
+		WindowSize: 2,
+		Layout:     "colemak",
+		Styles:     map[string]config.Style{"typed": {Foreground: "1"}},
+	}
+	if err := config.Save(conf); err != nil {
+		t.Fatalf("failed to save config: %v", err)
+	}
+	exerciseText := "one\ntwo\nthree\nfour\nfive"
+
+	testCases := []fromArgsViewOptionsTestCase{
+		{
+			args:         []string{""},
+			exerciseText: exerciseText,
+			check: func(got *viewOptions, gotErr error) {
+				name := "values from the config file"
+				if gotErr != nil {
+					t.Fatalf("%s wanted no error, got %s\n", name, gotErr)
+				}
+				if got.windowSize != 2 || got.layout.Name != "colemak" {
+					t.Fatalf("%s got window size %d and layout %s", name, got.windowSize, got.layout.Name)
+				}
+				if fg := got.styles.typedStyle.GetForeground(); fg != lg.Color("1") {
+					t.Fatalf("%s got typed foreground %v", name, fg)
+				}
+				if !got.styles.keyboardStyle.GetReverse() {
+					t.Fatalf("%s default keyboard should be reversed", name)
+				}
+			},
+		},
+		{
+			args:         []string{"--window-size", "3", "--layout", "qwerty"},
+			exerciseText: exerciseText,
+			check: func(got *viewOptions, gotErr error) {
+				name := "flags override the config file"
+				if gotErr != nil {
+					t.Fatalf("%s wanted no error, got %s\n", name, gotErr)
+				}
+				if got.windowSize != 3 || got.layout.Name != "qwerty" {
+					t.Fatalf("%s got window size %d and layout %s", name, got.windowSize, got.layout.Name)
+				}
+			},
+		},
+		{
+			args:         []string{"--theme", "solarized-dark"},
+			exerciseText: exerciseText,
+			check: func(got *viewOptions, gotErr error) {
+				name := "theme flag"
+				if gotErr != nil {
+					t.Fatalf("%s wanted no error, got %s\n", name, gotErr)
+				}
+				want := lg.Color(config.Themes["solarized-dark"]["cursor"].Background)
+				if bg := got.styles.cursorStyle.GetBackground(); bg != want {
+					t.Fatalf("%s got cursor background %v, want %v", name, bg, want)
+				}
+				if fg := got.styles.typedStyle.GetForeground(); fg != lg.Color("1") {
+					t.Fatalf("%s should still use the config file's styles, got typed foreground %v", name, fg)
+				}
+				if got.styles.keyboardStyle.GetReverse() {
+					t.Fatalf("%s keyboard with colors shouldn't be reversed", name)
+				}
+			},
+		},
+		{
+			args:         []string{"--theme", "nope"},
+			exerciseText: exerciseText,
+			check: func(got *viewOptions, gotErr error) {
+				if gotErr == nil {
+					t.Fatalf("unknown theme wanted error, got nil")
+				}
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		cmd := mockViewOptionsCmd(tc)
+		if err := cmd.Execute(); err != nil {
+			t.Fatalf("mock command failed to run: %s", err)
+		}
+	}
+}
+
+type fromArgsExerciseOptionsTestCase struct {
+	args  []string
+	check func(*exerciseOptions, error)
+}
+
+var mockExerciseOptionsCmd = func(tc fromArgsExerciseOptionsTestCase) *cobra.Command {
+	cmd := &cobra.Command{
+		Args: cobra.MaximumNArgs(1),
+		Run: func(cmd *cobra.Command, _ []string) {
+			got, gotErr := exerciseOptionsFromArgs(cmd)
+			tc.check(got, gotErr)
+		},
+	}
+	setRootCmdFlags(cmd)
+	cmd.SetArgs(tc.args)
+	return cmd
+}
+
+func Test_exerciseOptionsFromArgs(t *testing.T) {
+	testCases := []fromArgsExerciseOptionsTestCase{
+		{
+			args: []string{},
+			check: func(got *exerciseOptions, gotErr error) {
+				name := "default time limit should be 0"
+				if gotErr != nil {
+					t.Fatalf("%s wanted no error, got %s\n", name, gotErr)
+				}
+				if got.timeLimit != 0 {
+					t.Fatalf("%s got time limit %s", name, got.timeLimit)
+				}
+			},
+		},
+		{
+			args: []string{"--time", "60s"},
+			check: func(got *exerciseOptions, gotErr error) {
+				name := "passing a time limit of 60s"
+				want := time.Minute
+				if gotErr != nil {
+					t.Fatalf("%s wanted no error, got %s\n", name, gotErr)
+				}
+				if got.timeLimit != want {
+					t.Fatalf("%s got time limit %s, wanted %s", name, got.timeLimit, want)
+				}
+			},
+		},
+		{
+			args: []string{"--time", "-1s"},
+			check: func(got *exerciseOptions, gotErr error) {
+				name := "negative time limit"
+				if gotErr == nil {
+					t.Fatalf("%s wanted error, got nil\n", name)
+				}
+			},
+		},
+		{
+			args: []string{"--time", "1m30.5s"},
+			check: func(got *exerciseOptions, gotErr error) {
+				name := "time limit that isn't whole seconds"
+				want := 90*time.Second + 500*time.Millisecond
+				if gotErr != nil {
+					t.Fatalf("%s wanted no error, got %s\n", name, gotErr)
+				}
+				if got.timeLimit != want {
+					t.Fatalf("%s got time limit %s, wanted %s", name, got.timeLimit, want)
+				}
+			},
+		},
+		{
+			args: []string{"--ghost"},
+			check: func(got *exerciseOptions, gotErr error) {
+				name := "racing a ghost"
+				if gotErr != nil {
+					t.Fatalf("%s wanted no error, got %s\n", name, gotErr)
+				}
+				if !got.ghost {
+					t.Fatalf("%s wanted ghost to be enabled", name)
+				}
+			},
+		},
+		{
+			args: []string{"--strict"},
+			check: func(got *exerciseOptions, gotErr error) {
+				name := "strict mode"
+				if gotErr != nil {
+					t.Fatalf("%s wanted no error, got %s\n", name, gotErr)
+				}
+				if !got.strict {
+					t.Fatalf("%s wanted strict to be enabled", name)
+				}
+			},
+		},
+		{
+			args: []string{"--ghost", "--time", "60s"},
+			check: func(got *exerciseOptions, gotErr error) {
+				name := "racing a ghost in a timed exercise"
+				if gotErr == nil {
+					t.Fatalf("%s wanted error, got nil\n", name)
+				}
+			},
+		},
+		{
+			args: []string{},
+			check: func(got *exerciseOptions, gotErr error) {
+				name := "default idle timeout"
+				if gotErr != nil {
+					t.Fatalf("%s wanted no error, got %s\n", name, gotErr)
+				}
+				if got.idleTimeout != defaultIdleTimeout {
+					t.Fatalf("%s got idle timeout %s, wanted %s", name, got.idleTimeout, defaultIdleTimeout)
+				}
+			},
+		},
+		{
+			args: []string{"--idle", "0"},
+			check: func(got *exerciseOptions, gotErr error) {
+				name := "disabling idle detection"
+				if gotErr != nil {
+					t.Fatalf("%s wanted no error, got %s\n", name, gotErr)
+				}
+				if got.idleTimeout != 0 {
+					t.Fatalf("%s got idle timeout %s, wanted 0", name, got.idleTimeout)
+				}
+			},
+		},
+		{
+			args: []string{"--idle", "-1s"},
+			check: func(got *exerciseOptions, gotErr error) {
+				name := "negative idle timeout"
+				if gotErr == nil {
+					t.Fatalf("%s wanted error, got nil\n", name)
+				}
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		cmd := mockExerciseOptionsCmd(tc)
+		if err := cmd.Execute(); err != nil {
+			t.Fatalf("mock command failed to run: %s", err)
+		}
+	}
+}
+
 func Test_scanFileText(t *testing.T) {
 	// see sweet.go#setRootCmdFlags
 	defaultStart := uint(1)
@@ -630,3 +901,73 @@ func Test_scanFileText(t *testing.T) {
 		}
 	}
 }
+
+func Test_exerciseFileFromArgs_withSchedule(t *testing.T) {
+	tmpExercisesDir := t.TempDir()
+	t.Setenv("SWEET_EXERCISES_DIR", tmpExercisesDir)
+	t.Setenv("SWEET_DB_LOCATION", t.TempDir())
+	testExercises := []exerciseFile{
+		{name: "practiced.go", text: "fmt.Println(\"Hello!\")\n"},
+		{name: "new.go", text: "fmt.Println(\"New!\")\n"},
+	}
+	createExerciseFiles(t, tmpExercisesDir, testExercises)
+
+	statsDb, err := db.SweetDb()
+	if err != nil {
+		t.Fatalf("failed to open database: %v", err)
+	}
+	start := time.Now().Add(-time.Hour)
+	rep := db.Rep{Hash: "abc", Name: "practiced.go", Lang: "go", Start: start, End: start, Acc: 100}
+	if _, err := db.InsertRep(statsDb, rep); err != nil {
+		t.Fatalf("failed to insert rep: %v", err)
+	}
+	statsDb.Close()
+
+	testCases := []fromArgsExerciseFileTestCase{
+		{
+			args: []string{"--schedule", "srs"},
+			check: func(got exerciseFile, gotErr error) {
+				if gotErr != nil {
+					t.Fatalf("srs schedule wanted no error, got %s", gotErr)
+				}
+				if !got.matches(testExercises[1]) {
+					t.Errorf("srs schedule got %s, want the exercise that was never practiced", got.name)
+				}
+			},
+		},
+		{
+			args: []string{"--schedule", "weak", "-l", "go"},
+			check: func(got exerciseFile, gotErr error) {
+				if gotErr != nil {
+					t.Fatalf("weak schedule wanted no error, got %s", gotErr)
+				}
+				if !got.matchesOneOf(testExercises) {
+					t.Errorf("weak schedule got %s, want one of the exercises", got.name)
+				}
+			},
+		},
+		{
+			args: []string{"--schedule", "sometimes"},
+			check: func(got exerciseFile, gotErr error) {
+				if gotErr == nil {
+					t.Errorf("unknown schedule wanted error, got nil")
+				}
+			},
+		},
+		{
+			args: []string{path.Join(tmpExercisesDir, "new.go"), "--schedule", "srs"},
+			check: func(got exerciseFile, gotErr error) {
+				if gotErr == nil {
+					t.Errorf("schedule with a file wanted error, got nil")
+				}
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		cmd := mockExerciseFileCmd(tc)
+		if err := cmd.Execute(); err != nil {
+			t.Fatalf("mock command failed to run: %s", err)
+		}
+	}
+}
@@ -0,0 +1,62 @@
+package stats
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/NicksPatties/sweet/db"
+	"github.com/NicksPatties/sweet/event"
+	"github.com/NicksPatties/sweet/keyboard"
+	tw "github.com/olekukonko/tablewriter"
+)
+
+// Counts the attempts, misses, and latency of each finger of the
+// layout. Characters that need shift count towards both the finger
+// that types them and the pinky that holds shift.
+func fingerStatsFor(reps []db.Rep, layout keyboard.Layout) map[keyboard.Finger]*keyStats {
+	return tally(reps, func(expected string) []keyboard.Finger {
+		return layout.Fingers(event.EventTypedToRune(expected))
+	})
+}
+
+// Renders a table of the keystrokes, accuracy, and average latency
+// of each finger used to type the reps, from the left pinky to the
+// right pinky. Returns an empty string if no keys were typed.
+func RenderFingers(reps []db.Rep, layout keyboard.Layout) string {
+	stats := fingerStatsFor(reps, layout)
+	if len(stats) == 0 {
+		return ""
+	}
+	fingers := []keyboard.Finger{}
+	for f := range stats {
+		fingers = append(fingers, f)
+	}
+	sort.Slice(fingers, func(i, j int) bool { return fingers[i] < fingers[j] })
+
+	var sb strings.Builder
+	table := tw.NewWriter(&sb)
+	table.SetHeader([]string{"finger", "keys", "accuracy", "avg latency"})
+	table.SetAutoFormatHeaders(false)
+	for _, f := range fingers {
+		fs := stats[f]
+		table.Append([]string{
+			f.String(),
+			fmt.Sprint(fs.attempts),
+			fmt.Sprintf("%.2f%%", fs.accuracy()),
+			fs.latencyCell(),
+		})
+	}
+	table.Render()
+	return sb.String()
+}
+
+// Prints the stats of each finger used to type the reps.
+func renderFingerStats(reps []db.Rep, layout keyboard.Layout) {
+	table := RenderFingers(reps, layout)
+	if table == "" {
+		fmt.Println("no keystrokes")
+		return
+	}
+	fmt.Printf("fingers:\n%s", table)
+}
@@ -0,0 +1,83 @@
+package stats
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/NicksPatties/sweet/db"
+	"github.com/NicksPatties/sweet/event"
+	"github.com/NicksPatties/sweet/keyboard"
+)
+
+func TestFingerStatsFor(t *testing.T) {
+	start := time.Date(2024, 12, 6, 17, 36, 20, 0, time.UTC)
+	at := func(ms int) time.Time {
+		return start.Add(time.Duration(ms) * time.Millisecond)
+	}
+	reps := []db.Rep{
+		{
+			Events: event.Events{
+				{Ts: at(0), I: 0, Typed: "a", Expected: "a"},
+				{Ts: at(100), I: 1, Typed: "]", Expected: "["},
+				{Ts: at(200), I: 1, Typed: "backspace"},
+				{Ts: at(400), I: 1, Typed: "[", Expected: "["},
+				{Ts: at(700), I: 2, Typed: "{", Expected: "{"},
+			},
+		},
+	}
+	layout, err := keyboard.Get("qwerty")
+	if err != nil {
+		t.Fatalf("failed to get layout: %v", err)
+	}
+
+	got := fingerStatsFor(reps, layout)
+
+	want := map[keyboard.Finger]keyStats{
+		// holds shift for the "{"
+		keyboard.LeftPinky:  {attempts: 2, latency: 300 * time.Millisecond, timed: 1},
+		keyboard.RightPinky: {attempts: 3, misses: 1, latency: 500 * time.Millisecond, timed: 2},
+	}
+	if len(got) != len(want) {
+		t.Errorf("got stats for %d fingers, want %d: %v", len(got), len(want), got)
+	}
+	for f, w := range want {
+		g, ok := got[f]
+		if !ok {
+			t.Errorf("missing stats for %s", f)
+			continue
+		}
+		if *g != w {
+			t.Errorf("%s: got %+v, want %+v", f, *g, w)
+		}
+	}
+}
+
+func TestRenderFingers(t *testing.T) {
+	layout, err := keyboard.Get("qwerty")
+	if err != nil {
+		t.Fatalf("failed to get layout: %v", err)
+	}
+	if got := RenderFingers([]db.Rep{{}}, layout); got != "" {
+		t.Errorf("wanted nothing for a rep without events, got:\n%s", got)
+	}
+
+	start := time.Date(2024, 12, 6, 17, 36, 20, 0, time.UTC)
+	reps := []db.Rep{
+		{
+			Events: event.Events{
+				{Ts: start, I: 0, Typed: "j", Expected: "j"},
+				{Ts: start.Add(250 * time.Millisecond), I: 1, Typed: "f", Expected: "f"},
+			},
+		},
+	}
+	got := RenderFingers(reps, layout)
+	left := strings.Index(got, "left index")
+	right := strings.Index(got, "right index")
+	if left < 0 || right < 0 || left > right {
+		t.Errorf("wanted left index before right index, got:\n%s", got)
+	}
+	if !strings.Contains(got, "250ms") {
+		t.Errorf("wanted the left index's latency, got:\n%s", got)
+	}
+}
@@ -0,0 +1,224 @@
+package stats
+
+import (
+	"cmp"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	c "github.com/NicksPatties/sweet/constants"
+	"github.com/NicksPatties/sweet/db"
+	g "github.com/guptarohit/asciigraph"
+	tw "github.com/olekukonko/tablewriter"
+)
+
+// The periods reps can be grouped by.
+const (
+	DAY   = "day"
+	WEEK  = "week"
+	MONTH = "month"
+)
+
+var periods = []string{DAY, WEEK, MONTH}
+
+// A group of reps, like the reps that started in the same
+// period, or the reps of the same exercise.
+type bucket struct {
+	// Names the group in tables.
+	label string
+
+	// Midnight of the first day of the group's period, in local
+	// time. Zero if the reps aren't grouped by period.
+	start time.Time
+
+	// Sorted by when they started.
+	reps []db.Rep
+}
+
+func (b bucket) avgWpm() (avg float64) {
+	for _, rep := range b.reps {
+		avg += rep.Wpm
+	}
+	return avg / float64(len(b.reps))
+}
+
+func (b bucket) bestWpm() (best float64) {
+	for _, rep := range b.reps {
+		best = max(best, rep.Wpm)
+	}
+	return
+}
+
+func (b bucket) avgAcc() (avg float64) {
+	for _, rep := range b.reps {
+		avg += rep.Acc
+	}
+	return avg / float64(len(b.reps))
+}
+
+func (b bucket) latest() db.Rep {
+	return b.reps[len(b.reps)-1]
+}
+
+// Returns the total time spent typing the reps.
+func (b bucket) practiced() (d time.Duration) {
+	for _, rep := range b.reps {
+		d += rep.Dur
+	}
+	return
+}
+
+// Returns midnight of the first day of the period t is in, in local
+// time. Weeks start on Monday.
+func periodStart(t time.Time, period string) time.Time {
+	t = t.Local()
+	day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.Local)
+	switch period {
+	case WEEK:
+		// Sunday is 0, so it's six days after Monday
+		return day.AddDate(0, 0, -(int(day.Weekday())+6)%7)
+	case MONTH:
+		return day.AddDate(0, 0, 1-day.Day())
+	default:
+		return day
+	}
+}
+
+// Groups the reps by the period they started in. The reps must be
+// sorted by when they started. Periods without reps are skipped.
+func groupReps(reps []db.Rep, period string) ([]bucket, error) {
+	if !slices.Contains(periods, period) {
+		return nil, fmt.Errorf("can't group by %s (use one of %s)", period, strings.Join(periods, ", "))
+	}
+	buckets := []bucket{}
+	for _, rep := range reps {
+		start := periodStart(rep.Start, period)
+		if len(buckets) == 0 || !buckets[len(buckets)-1].start.Equal(start) {
+			buckets = append(buckets, bucket{label: periodLabel(start, period), start: start})
+		}
+		last := &buckets[len(buckets)-1]
+		last.reps = append(last.reps, rep)
+	}
+	return buckets, nil
+}
+
+// Formats the start of a bucket's period.
+func periodLabel(start time.Time, period string) string {
+	if period == MONTH {
+		return start.Format("2006-01")
+	}
+	return start.Format(time.DateOnly)
+}
+
+// Plots the average and best wpm, and the average
+// accuracy, of each bucket.
+func renderBucketGraph(buckets []bucket) {
+	avgWpm := []float64{}
+	bestWpm := []float64{}
+	avgAcc := []float64{}
+	for _, b := range buckets {
+		avgWpm = append(avgWpm, b.avgWpm())
+		bestWpm = append(bestWpm, b.bestWpm())
+		avgAcc = append(avgAcc, b.avgAcc())
+	}
+
+	graph := g.PlotMany(
+		[][]float64{avgAcc, bestWpm, avgWpm},
+		g.SeriesColors(g.Green, g.Gray, g.Default),
+		g.SeriesLegends("avg accuracy", "best wpm", "avg wpm"),
+		g.Height(10),
+		g.Width(0), // auto scaling
+		g.LowerBound(0),
+		g.Precision(0),
+	)
+
+	fmt.Println(graph)
+}
+
+// Prints a row for each bucket, labeled by the start of its period.
+func renderBuckets(period string, buckets []bucket) {
+	table := tw.NewWriter(os.Stdout)
+	table.SetHeader([]string{period, "reps", "avg wpm", "best wpm", "avg acc", "time"})
+	table.SetAutoFormatHeaders(false)
+	for _, b := range buckets {
+		table.Append([]string{
+			b.label,
+			fmt.Sprint(len(b.reps)),
+			fmt.Sprintf("%.f", b.avgWpm()),
+			fmt.Sprintf("%.f", b.bestWpm()),
+			fmt.Sprintf("%.2f%%", b.avgAcc()),
+			b.practiced().Round(time.Second).String(),
+		})
+	}
+	table.Render()
+}
+
+// The fields reps can be broken down by.
+const (
+	BY_EXERCISE = "exercise"
+	BY_LANGUAGE = c.LANGUAGE
+)
+
+var breakdowns = []string{BY_EXERCISE, BY_LANGUAGE}
+
+// Groups the reps by their exercise or their language. Exercises
+// are told apart by their names and hashes, so each version of a
+// changed exercise is its own group, and so is each file with the
+// same text. Generated exercises, like drills, are grouped by their
+// names alone. The groups are sorted from the lowest average wpm to
+// the highest, so the weakest ones come first.
+func breakDownReps(reps []db.Rep, by string) ([]bucket, error) {
+	if !slices.Contains(breakdowns, by) {
+		return nil, fmt.Errorf("can't break down by %s (use one of %s)", by, strings.Join(breakdowns, ", "))
+	}
+	labels := ExerciseLabels(reps)
+	index := map[string]int{}
+	buckets := []bucket{}
+	for _, rep := range reps {
+		key := rep.Lang
+		if by == BY_EXERCISE {
+			key = ExerciseKey(rep)
+		}
+		i, ok := index[key]
+		if !ok {
+			i = len(buckets)
+			index[key] = i
+			buckets = append(buckets, bucket{})
+		}
+		buckets[i].reps = append(buckets[i].reps, rep)
+		buckets[i].label = rep.Lang
+		if by == BY_EXERCISE {
+			buckets[i].label = labels[ExerciseKey(rep)]
+		}
+	}
+	slices.SortStableFunc(buckets, func(a, b bucket) int {
+		if a.avgWpm() != b.avgWpm() {
+			return cmp.Compare(a.avgWpm(), b.avgWpm())
+		}
+		return strings.Compare(a.label, b.label)
+	})
+	return buckets, nil
+}
+
+// Prints a row for each exercise or language, with its
+// wpm, accuracy, and when it was last practiced.
+func renderBreakdown(by string, buckets []bucket) {
+	table := tw.NewWriter(os.Stdout)
+	table.SetHeader([]string{by, "reps", "avg wpm", "best wpm", "latest wpm", "avg acc", "last practiced"})
+	table.SetAutoFormatHeaders(false)
+	for _, b := range buckets {
+		latest := b.latest()
+		table.Append([]string{
+			b.label,
+			fmt.Sprint(len(b.reps)),
+			fmt.Sprintf("%.f", b.avgWpm()),
+			fmt.Sprintf("%.f", b.bestWpm()),
+			fmt.Sprintf("%.f", latest.Wpm),
+			fmt.Sprintf("%.2f%%", b.avgAcc()),
+			latest.Start.Local().Format(time.DateOnly),
+		})
+	}
+	table.Render()
+}
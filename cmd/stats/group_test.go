@@ -0,0 +1,134 @@
+package stats
+
+import (
+	"testing"
+	"time"
+
+	"github.com/NicksPatties/sweet/db"
+)
+
+func TestPeriodStart(t *testing.T) {
+	// a Sunday
+	sunday := time.Date(2024, 12, 8, 17, 36, 20, 0, time.Local)
+	testCases := []struct {
+		in     time.Time
+		period string
+		want   time.Time
+	}{
+		{sunday, DAY, time.Date(2024, 12, 8, 0, 0, 0, 0, time.Local)},
+		{sunday, WEEK, time.Date(2024, 12, 2, 0, 0, 0, 0, time.Local)},
+		{sunday.AddDate(0, 0, 1), WEEK, time.Date(2024, 12, 9, 0, 0, 0, 0, time.Local)},
+		{sunday, MONTH, time.Date(2024, 12, 1, 0, 0, 0, 0, time.Local)},
+	}
+	for _, tc := range testCases {
+		if got := periodStart(tc.in, tc.period); !got.Equal(tc.want) {
+			t.Errorf("%s of %s: got %s, want %s", tc.period, tc.in, got, tc.want)
+		}
+	}
+}
+
+func TestGroupReps(t *testing.T) {
+	at := func(day int, hour int) time.Time {
+		return time.Date(2024, 12, day, hour, 0, 0, 0, time.Local)
+	}
+	reps := []db.Rep{
+		{Start: at(2, 9), Wpm: 40, Acc: 90, Dur: time.Minute},
+		{Start: at(2, 21), Wpm: 60, Acc: 100, Dur: 30 * time.Second},
+		{Start: at(4, 9), Wpm: 50, Acc: 95, Dur: time.Minute},
+		{Start: at(10, 9), Wpm: 70, Acc: 99, Dur: time.Minute},
+	}
+
+	testCases := []struct {
+		period string
+		want   []int
+	}{
+		{DAY, []int{2, 1, 1}},
+		{WEEK, []int{3, 1}},
+		{MONTH, []int{4}},
+	}
+	for _, tc := range testCases {
+		buckets, err := groupReps(reps, tc.period)
+		if err != nil {
+			t.Fatalf("%s: failed to group reps: %v", tc.period, err)
+		}
+		if len(buckets) != len(tc.want) {
+			t.Fatalf("%s: got %d buckets, want %d", tc.period, len(buckets), len(tc.want))
+		}
+		for i, b := range buckets {
+			if len(b.reps) != tc.want[i] {
+				t.Errorf("%s: bucket %d has %d reps, want %d", tc.period, i, len(b.reps), tc.want[i])
+			}
+		}
+	}
+
+	buckets, _ := groupReps(reps, WEEK)
+	first := buckets[0]
+	if got := first.avgWpm(); got != 50 {
+		t.Errorf("got average wpm %.2f, want 50", got)
+	}
+	if got := first.bestWpm(); got != 60 {
+		t.Errorf("got best wpm %.2f, want 60", got)
+	}
+	if got := first.avgAcc(); got != 95 {
+		t.Errorf("got average accuracy %.2f, want 95", got)
+	}
+	if got := first.practiced(); got != 150*time.Second {
+		t.Errorf("got practice time %s, want 2m30s", got)
+	}
+	if got := first.label; got != "2024-12-02" {
+		t.Errorf("got label %s, want 2024-12-02", got)
+	}
+
+	if _, err := groupReps(reps, "fortnight"); err == nil {
+		t.Errorf("wanted error for an unknown period, got nil")
+	}
+}
+
+func TestBreakDownReps(t *testing.T) {
+	at := func(day int) time.Time {
+		return time.Date(2024, 12, day, 9, 0, 0, 0, time.Local)
+	}
+	reps := []db.Rep{
+		{Hash: "a", Name: "a.go", Lang: "go", Start: at(1), Wpm: 80, Acc: 100},
+		{Hash: "b", Name: "b.py", Lang: "py", Start: at(2), Wpm: 40, Acc: 90},
+		// same text as a.go, but a different file
+		{Hash: "a", Name: "copy.go", Lang: "go", Start: at(3), Wpm: 55, Acc: 95},
+		{Hash: "c", Name: "c.go", Lang: "go", Start: at(4), Wpm: 50, Acc: 98},
+		{Hash: "a", Name: "a.go", Lang: "go", Start: at(5), Wpm: 60, Acc: 96},
+	}
+
+	testCases := []struct {
+		by         string
+		wantLabels []string
+		wantReps   []int
+	}{
+		{BY_EXERCISE, []string{"b.py", "c.go", "copy.go", "a.go"}, []int{1, 1, 1, 2}},
+		{BY_LANGUAGE, []string{"py", "go"}, []int{1, 4}},
+	}
+	for _, tc := range testCases {
+		buckets, err := breakDownReps(reps, tc.by)
+		if err != nil {
+			t.Fatalf("%s: failed to break down reps: %v", tc.by, err)
+		}
+		if len(buckets) != len(tc.wantLabels) {
+			t.Fatalf("%s: got %d groups, want %d", tc.by, len(buckets), len(tc.wantLabels))
+		}
+		for i, b := range buckets {
+			if b.label != tc.wantLabels[i] || len(b.reps) != tc.wantReps[i] {
+				t.Errorf("%s: got group %s with %d reps, want %s with %d",
+					tc.by, b.label, len(b.reps), tc.wantLabels[i], tc.wantReps[i])
+			}
+		}
+	}
+
+	buckets, _ := breakDownReps(reps, BY_EXERCISE)
+	a := buckets[3]
+	if a.avgWpm() != 70 || a.bestWpm() != 80 || a.latest().Wpm != 60 || a.avgAcc() != 98 {
+		t.Errorf("got avg %.f, best %.f, latest %.f wpm and %.2f%% accuracy, want 70, 80, 60, and 98%%",
+			a.avgWpm(), a.bestWpm(), a.latest().Wpm, a.avgAcc())
+	}
+
+	if _, err := breakDownReps(reps, "hash"); err == nil {
+		t.Errorf("wanted error for an unknown breakdown, got nil")
+	}
+}
@@ -0,0 +1,223 @@
+package stats
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/NicksPatties/sweet/db"
+	"github.com/NicksPatties/sweet/event"
+	"github.com/NicksPatties/sweet/keyboard"
+	lg "github.com/charmbracelet/lipgloss"
+	tw "github.com/olekukonko/tablewriter"
+)
+
+// How often keystrokes were typed correctly, and how long
+// it took to type them.
+type keyStats struct {
+	// Number of times the keystroke was expected.
+	attempts int
+
+	// Number of times a different key was typed instead.
+	misses int
+
+	// Total time it took to type the keystroke correctly, and the
+	// number of times it was typed correctly after another keystroke.
+	latency time.Duration
+	timed   int
+}
+
+func (k keyStats) missRate() float64 {
+	if k.attempts == 0 {
+		return 0
+	}
+	return float64(k.misses) / float64(k.attempts) * 100
+}
+
+func (k keyStats) accuracy() float64 {
+	if k.attempts == 0 {
+		return 0
+	}
+	return 100 - k.missRate()
+}
+
+func (k keyStats) avgLatency() time.Duration {
+	if k.timed == 0 {
+		return 0
+	}
+	return k.latency / time.Duration(k.timed)
+}
+
+// Formats the average latency for a table, or `-` if
+// the keystroke was never timed.
+func (k keyStats) latencyCell() string {
+	if k.timed == 0 {
+		return "-"
+	}
+	return k.avgLatency().Round(time.Millisecond).String()
+}
+
+// Counts the attempts, misses, and latency of the keystrokes of the
+// reps, grouped by what groups returns for each expected character.
+// A keystroke counts towards every group it's in, and keystrokes
+// without a group aren't counted. The latency of a keystroke is the
+// time since the keystroke before it, without the time spent paused.
+func tally[K comparable](reps []db.Rep, groups func(expected string) []K) map[K]*keyStats {
+	stats := map[K]*keyStats{}
+	for _, rep := range reps {
+		events := event.RemovePauses(rep.Events, rep.Pauses)
+		for i, e := range events {
+			if e.Typed == "backspace" || e.Expected == "" {
+				continue
+			}
+			for _, group := range groups(e.Expected) {
+				if stats[group] == nil {
+					stats[group] = &keyStats{}
+				}
+				ks := stats[group]
+				ks.attempts++
+				if e.Typed != e.Expected {
+					ks.misses++
+				} else if i > 0 {
+					ks.latency += e.Ts.Sub(events[i-1].Ts)
+					ks.timed++
+				}
+			}
+		}
+	}
+	return stats
+}
+
+// Counts the attempts, misses, and latency of each key of the
+// layout. Characters that need shift count towards the key that's
+// pressed with it.
+func keyStatsFor(reps []db.Rep, layout keyboard.Layout) map[string]*keyStats {
+	return tally(reps, func(expected string) []string {
+		key := layout.Key(string(event.EventTypedToRune(expected)))
+		if key == "" {
+			return nil
+		}
+		return []string{key}
+	})
+}
+
+// Colors of the heatmap, from the best keys to the worst.
+var heatColors = []lg.Color{"28", "70", "142", "208", "196"}
+
+// Returns the color of a value between the smallest and
+// the largest values of a heatmap.
+func heatColor(value float64, min float64, max float64) lg.Color {
+	if max <= min {
+		return heatColors[0]
+	}
+	i := int((value - min) / (max - min) * float64(len(heatColors)))
+	if i >= len(heatColors) {
+		i = len(heatColors) - 1
+	}
+	return heatColors[i]
+}
+
+// Renders the layout's keys colored by one of their stats. Keys
+// without a value aren't colored. A legend with the smallest and
+// largest values follows the keyboard.
+func renderHeatmap(
+	title string,
+	layout keyboard.Layout,
+	stats map[string]*keyStats,
+	value func(keyStats) (float64, bool),
+	format func(float64) string,
+) string {
+	min, max := 0.0, 0.0
+	first := true
+	for _, ks := range stats {
+		v, ok := value(*ks)
+		if !ok {
+			continue
+		}
+		if first || v < min {
+			min = v
+		}
+		if first || v > max {
+			max = v
+		}
+		first = false
+	}
+
+	keys := layout.RenderKeys(func(key string) lg.Style {
+		ks, ok := stats[key]
+		if !ok {
+			return lg.NewStyle().Faint(true)
+		}
+		v, ok := value(*ks)
+		if !ok {
+			return lg.NewStyle().Faint(true)
+		}
+		return lg.NewStyle().
+			Foreground(lg.Color("0")).
+			Background(heatColor(v, min, max))
+	})
+
+	legend := ""
+	for _, c := range heatColors {
+		legend += lg.NewStyle().Foreground(c).Render("■")
+	}
+	return fmt.Sprintf("%s:\n%s\n%s %s %s\n", title, keys, format(min), legend, format(max))
+}
+
+// Prints the missed keys with the highest miss rates, and their stats.
+func renderWorstKeys(stats map[string]*keyStats, limit int) {
+	keys := []string{}
+	for key, ks := range stats {
+		if ks.misses > 0 {
+			keys = append(keys, key)
+		}
+	}
+	if len(keys) == 0 {
+		fmt.Println("no missed keys")
+		return
+	}
+	sort.Strings(keys)
+	sort.SliceStable(keys, func(i, j int) bool {
+		return stats[keys[i]].missRate() > stats[keys[j]].missRate()
+	})
+	if len(keys) > limit {
+		keys = keys[:limit]
+	}
+
+	table := tw.NewWriter(os.Stdout)
+	table.SetHeader([]string{"key", "typed", "missed", "miss rate", "avg latency"})
+	table.SetAutoFormatHeaders(false)
+	for _, key := range keys {
+		ks := stats[key]
+		table.Append([]string{
+			key,
+			fmt.Sprint(ks.attempts),
+			fmt.Sprint(ks.misses),
+			fmt.Sprintf("%.2f%%", ks.missRate()),
+			ks.latencyCell(),
+		})
+	}
+	table.Render()
+}
+
+// Renders heatmaps of each key's miss rate and average
+// latency, followed by the keys that are missed the most.
+func renderKeys(reps []db.Rep, layout keyboard.Layout) {
+	stats := keyStatsFor(reps, layout)
+	if len(stats) == 0 {
+		fmt.Println("no keystrokes")
+		return
+	}
+
+	fmt.Println(renderHeatmap("miss rate", layout, stats,
+		func(ks keyStats) (float64, bool) { return ks.missRate(), true },
+		func(v float64) string { return fmt.Sprintf("%.2f%%", v) },
+	))
+	fmt.Println(renderHeatmap("average latency", layout, stats,
+		func(ks keyStats) (float64, bool) { return float64(ks.avgLatency()), ks.timed > 0 },
+		func(v float64) string { return time.Duration(v).Round(time.Millisecond).String() },
+	))
+	fmt.Println("most missed keys:")
+	renderWorstKeys(stats, 10)
+}
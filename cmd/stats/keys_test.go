@@ -0,0 +1,89 @@
+package stats
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/NicksPatties/sweet/db"
+	"github.com/NicksPatties/sweet/event"
+	"github.com/NicksPatties/sweet/keyboard"
+	lg "github.com/charmbracelet/lipgloss"
+)
+
+func TestKeyStatsFor(t *testing.T) {
+	start := time.Date(2024, 12, 6, 17, 36, 20, 0, time.UTC)
+	at := func(ms int) time.Time {
+		return start.Add(time.Duration(ms) * time.Millisecond)
+	}
+	reps := []db.Rep{
+		{
+			Events: event.Events{
+				{Ts: at(0), I: 0, Typed: "H", Expected: "H"},
+				{Ts: at(100), I: 1, Typed: "w", Expected: "i"},
+				{Ts: at(200), I: 1, Typed: "backspace"},
+				{Ts: at(500), I: 1, Typed: "i", Expected: "i"},
+				// paused for a second before this keystroke
+				{Ts: at(1700), I: 2, Typed: "enter", Expected: "enter"},
+				{Ts: at(1800), I: 3, Typed: "é", Expected: "é"},
+			},
+			Pauses: event.Pauses{{Start: at(600), End: at(1600)}},
+		},
+		{
+			Events: event.Events{
+				{Ts: at(0), I: 0, Typed: "h", Expected: "h"},
+				{Ts: at(200), I: 1, Typed: "i", Expected: "i"},
+			},
+		},
+	}
+	layout, err := keyboard.Get("qwerty")
+	if err != nil {
+		t.Fatalf("failed to get layout: %v", err)
+	}
+
+	got := keyStatsFor(reps, layout)
+
+	want := map[string]keyStats{
+		// the first keystroke of a rep has no latency
+		"h": {attempts: 2, timed: 0},
+		"i": {attempts: 3, misses: 1, latency: 500 * time.Millisecond, timed: 2},
+		"↲": {attempts: 1, latency: 200 * time.Millisecond, timed: 1},
+	}
+	if len(got) != len(want) {
+		t.Errorf("got stats for %d keys, want %d: %v", len(got), len(want), got)
+	}
+	for key, w := range want {
+		g, ok := got[key]
+		if !ok {
+			t.Errorf("missing stats for %q", key)
+			continue
+		}
+		if *g != w {
+			t.Errorf("%q: got %+v, want %+v", key, *g, w)
+		}
+	}
+	if rate := fmt.Sprintf("%.2f", got["i"].missRate()); rate != "33.33" {
+		t.Errorf("got miss rate %s, want 33.33", rate)
+	}
+	if latency := got["i"].avgLatency(); latency != 250*time.Millisecond {
+		t.Errorf("got average latency %s, want 250ms", latency)
+	}
+}
+
+func TestHeatColor(t *testing.T) {
+	testCases := []struct {
+		value, min, max float64
+		want            lg.Color
+	}{
+		{0, 0, 10, heatColors[0]},
+		{5, 0, 10, heatColors[2]},
+		{9.9, 0, 10, heatColors[4]},
+		{10, 0, 10, heatColors[4]},
+		{3, 3, 3, heatColors[0]},
+	}
+	for _, tc := range testCases {
+		if got := heatColor(tc.value, tc.min, tc.max); got != tc.want {
+			t.Errorf("heatColor(%v, %v, %v) got %v, want %v", tc.value, tc.min, tc.max, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,153 @@
+package stats
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/NicksPatties/sweet/db"
+	"github.com/NicksPatties/sweet/event"
+	tw "github.com/olekukonko/tablewriter"
+)
+
+// The shortest and longest sequences of keys that are analyzed.
+const (
+	minNgram = 2
+	maxNgram = 3
+)
+
+// Sequences typed fewer times than this aren't reported,
+// since one slow or missed keystroke would skew them.
+const minNgramCount = 3
+
+// A keystroke that was typed correctly.
+type correctKey struct {
+	char rune
+	e    event.Event
+}
+
+// Counts the attempts, misses, and latency of each sequence of two
+// and three characters in the reps. A sequence is attempted when its
+// last character is expected right after the others were typed
+// correctly, and it's missed if that character is mistyped. Its
+// latency is the time from its first keystroke to its last, and is
+// only counted if every keystroke of the sequence was correct.
+// Backspaces and mistakes start new sequences. Time spent paused
+// isn't counted.
+func ngramStatsFor(reps []db.Rep) map[string]*keyStats {
+	stats := map[string]*keyStats{}
+	for _, rep := range reps {
+		run := []correctKey{}
+		for _, e := range event.RemovePauses(rep.Events, rep.Pauses) {
+			if e.Typed == "backspace" || e.Expected == "" {
+				run = run[:0]
+				continue
+			}
+			char := event.EventTypedToRune(e.Expected)
+			correct := e.Typed == e.Expected
+			for n := minNgram; n <= maxNgram && n-1 <= len(run); n++ {
+				prev := run[len(run)-(n-1):]
+				ngram := ""
+				for _, k := range prev {
+					ngram += string(k.char)
+				}
+				ngram += string(char)
+				if stats[ngram] == nil {
+					stats[ngram] = &keyStats{}
+				}
+				ns := stats[ngram]
+				ns.attempts++
+				if !correct {
+					ns.misses++
+					continue
+				}
+				ns.latency += e.Ts.Sub(prev[0].e.Ts)
+				ns.timed++
+			}
+			if correct {
+				run = append(run, correctKey{char, e})
+				if len(run) >= maxNgram {
+					run = run[len(run)-(maxNgram-1):]
+				}
+			} else {
+				run = run[:0]
+			}
+		}
+	}
+	return stats
+}
+
+// Shows whitespace in a sequence, so it can be read in a table.
+var ngramReplacer = strings.NewReplacer("\n", "↲", " ", "␣", "\t", "⇥")
+
+// Prints a table of up to limit sequences, sorted by less. Only
+// sequences of length n that were attempted enough times, and for
+// which include returns true, are shown.
+func renderNgramTable(
+	title string,
+	stats map[string]*keyStats,
+	n int,
+	limit int,
+	include func(keyStats) bool,
+	less func(a, b keyStats) bool,
+) {
+	ngrams := []string{}
+	for ngram, ns := range stats {
+		if len([]rune(ngram)) == n && ns.attempts >= minNgramCount && include(*ns) {
+			ngrams = append(ngrams, ngram)
+		}
+	}
+	fmt.Printf("%s:\n", title)
+	if len(ngrams) == 0 {
+		fmt.Printf("none typed at least %d times\n", minNgramCount)
+		return
+	}
+	sort.Strings(ngrams)
+	sort.SliceStable(ngrams, func(i, j int) bool {
+		return less(*stats[ngrams[i]], *stats[ngrams[j]])
+	})
+	if len(ngrams) > limit {
+		ngrams = ngrams[:limit]
+	}
+
+	table := tw.NewWriter(os.Stdout)
+	table.SetHeader([]string{"keys", "typed", "missed", "miss rate", "avg latency"})
+	table.SetAutoFormatHeaders(false)
+	for _, ngram := range ngrams {
+		ns := stats[ngram]
+		table.Append([]string{
+			ngramReplacer.Replace(ngram),
+			fmt.Sprint(ns.attempts),
+			fmt.Sprint(ns.misses),
+			fmt.Sprintf("%.2f%%", ns.missRate()),
+			ns.latencyCell(),
+		})
+	}
+	table.Render()
+}
+
+// Prints the slowest and most missed sequences of two
+// and three keys typed in the reps.
+func renderNgrams(reps []db.Rep) {
+	stats := ngramStatsFor(reps)
+	if len(stats) == 0 {
+		fmt.Println("no keystrokes")
+		return
+	}
+	names := map[int]string{2: "bigrams", 3: "trigrams"}
+	for n := minNgram; n <= maxNgram; n++ {
+		if n > minNgram {
+			fmt.Println()
+		}
+		renderNgramTable("slowest "+names[n], stats, n, 10,
+			func(ns keyStats) bool { return ns.timed >= minNgramCount },
+			func(a, b keyStats) bool { return a.avgLatency() > b.avgLatency() },
+		)
+		fmt.Println()
+		renderNgramTable("most missed "+names[n], stats, n, 10,
+			func(ns keyStats) bool { return ns.misses > 0 },
+			func(a, b keyStats) bool { return a.missRate() > b.missRate() },
+		)
+	}
+}
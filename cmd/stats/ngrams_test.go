@@ -0,0 +1,55 @@
+package stats
+
+import (
+	"testing"
+	"time"
+
+	"github.com/NicksPatties/sweet/db"
+	"github.com/NicksPatties/sweet/event"
+)
+
+func TestNgramStatsFor(t *testing.T) {
+	start := time.Date(2024, 12, 6, 17, 36, 20, 0, time.UTC)
+	at := func(ms int) time.Time {
+		return start.Add(time.Duration(ms) * time.Millisecond)
+	}
+	reps := []db.Rep{
+		{
+			Events: event.Events{
+				{Ts: at(0), I: 0, Typed: "e", Expected: "e"},
+				{Ts: at(100), I: 1, Typed: "r", Expected: "r"},
+				{Ts: at(300), I: 2, Typed: "t", Expected: "r"},
+				{Ts: at(400), I: 2, Typed: "backspace"},
+				{Ts: at(500), I: 2, Typed: "r", Expected: "r"},
+				// paused for a second before this keystroke
+				{Ts: at(1700), I: 3, Typed: "enter", Expected: "enter"},
+				{Ts: at(1800), I: 4, Typed: "}", Expected: "}"},
+			},
+			Pauses: event.Pauses{{Start: at(600), End: at(1600)}},
+		},
+	}
+
+	got := ngramStatsFor(reps)
+
+	want := map[string]keyStats{
+		"er":   {attempts: 1, latency: 100 * time.Millisecond, timed: 1},
+		"rr":   {attempts: 1, misses: 1},
+		"err":  {attempts: 1, misses: 1},
+		"r\n":  {attempts: 1, latency: 200 * time.Millisecond, timed: 1},
+		"\n}":  {attempts: 1, latency: 100 * time.Millisecond, timed: 1},
+		"r\n}": {attempts: 1, latency: 300 * time.Millisecond, timed: 1},
+	}
+	if len(got) != len(want) {
+		t.Errorf("got stats for %d sequences, want %d: %v", len(got), len(want), got)
+	}
+	for ngram, w := range want {
+		g, ok := got[ngram]
+		if !ok {
+			t.Errorf("missing stats for %q", ngram)
+			continue
+		}
+		if *g != w {
+			t.Errorf("%q: got %+v, want %+v", ngram, *g, w)
+		}
+	}
+}
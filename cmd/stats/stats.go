@@ -14,8 +14,10 @@ import (
 	tw "github.com/olekukonko/tablewriter"
 	"github.com/spf13/cobra"
 
+	"github.com/NicksPatties/sweet/config"
 	c "github.com/NicksPatties/sweet/constants"
 	db "github.com/NicksPatties/sweet/db"
+	"github.com/NicksPatties/sweet/keyboard"
 )
 
 type tmplData map[string]any
@@ -36,18 +38,94 @@ var Cmd = &cobra.Command{
 		"  sweet stats --name=hello.go\n\n" +
 		"  get stats for exercises that contain the name \"hello\"\n" +
 		"  sweet stats --name=hello*\n\n" +
+		"  get stats for one version of an exercise that was changed\n" +
+		"  sweet stats --name=hello.go --hash=1a2b3c4\n\n" +
 		"  get stats for words per minute and mistakes only\n" +
-		"  sweet stats --wpm --miss",
+		"  sweet stats --wpm --miss\n\n" +
+		"  get a heatmap of the keys you miss from the past month\n" +
+		"  sweet stats --since=1m --keys\n\n" +
+		"  get the accuracy and speed of each finger from the past month\n" +
+		"  sweet stats --since=1m --fingers\n\n" +
+		"  get the slowest and most missed key sequences of Go exercises\n" +
+		"  sweet stats --since=1m --lang=go --ngrams\n\n" +
+		"  get your average and best wpm of each week this year\n" +
+		"  sweet stats --since=1y --group-by=week\n\n" +
+		"  find the exercises you're slowest at this month\n" +
+		"  sweet stats --since=1m --by=exercise",
 	RunE: func(cmd *cobra.Command, args []string) error {
-		q, err := argsToQuery(cmd, time.Now())
+		reps, err := FilteredReps(cmd, time.Now())
 		if err != nil {
 			return err
 		}
-		reps, err := queryToReps(q)
+		conf, err := config.Load()
 		if err != nil {
 			return err
 		}
-		render(cmd, reps)
+		keys, _ := cmd.Flags().GetBool("keys")
+		fingers, _ := cmd.Flags().GetBool("fingers")
+		ngrams, _ := cmd.Flags().GetBool("ngrams")
+		if keys || fingers || ngrams {
+			layoutName := keyboard.DefaultLayout
+			if conf.Layout != "" {
+				layoutName = conf.Layout
+			}
+			layout, err := keyboard.Get(layoutName)
+			if err != nil {
+				return err
+			}
+			if err := loadEvents(reps); err != nil {
+				return err
+			}
+			sections := []func(){}
+			if keys {
+				sections = append(sections, func() { renderKeys(reps, layout) })
+			}
+			if fingers {
+				sections = append(sections, func() { renderFingerStats(reps, layout) })
+			}
+			if ngrams {
+				sections = append(sections, func() { renderNgrams(reps) })
+			}
+			renderFlagsHeader(cmd)
+			for i, section := range sections {
+				if i > 0 {
+					fmt.Println()
+				}
+				section()
+			}
+			return nil
+		}
+		defaultCols := defaultColumns
+		if len(conf.StatsColumns) > 0 {
+			defaultCols = conf.StatsColumns
+		}
+		cols := argsToColumnFilter(cmd, defaultCols)
+		period := cmd.Flag("group-by").Value.String()
+		if by := cmd.Flag("by").Value.String(); by != "" {
+			if period != "" {
+				return fmt.Errorf("use either the by or group-by flag, not both")
+			}
+			buckets, err := breakDownReps(reps, by)
+			if err != nil {
+				return err
+			}
+			renderFlagsHeader(cmd)
+			if len(buckets) == 0 {
+				fmt.Println("no stats")
+			} else {
+				renderBreakdown(by, buckets)
+			}
+			return nil
+		}
+		if period != "" {
+			buckets, err := groupReps(reps, period)
+			if err != nil {
+				return err
+			}
+			renderGrouped(cmd, reps, cols, period, buckets)
+			return nil
+		}
+		render(cmd, reps, cols)
 		return nil
 	},
 }
@@ -117,65 +195,81 @@ func parseDateFromArg(isEnd bool, arg string, now time.Time) (time.Time, error)
 	}
 }
 
-// Converts the flags assigned to the stats command into an SQLite query,
-// retrieving all entries from the database that match the query.
-func argsToQuery(cmd *cobra.Command, now time.Time) (string, error) {
-	filters := []string{}
-	name := cmd.Flag(c.NAME).Value.String()
-	lang := cmd.Flag(c.LANGUAGE).Value.String()
-
-	if name != "" && lang != "" {
-		return "", fmt.Errorf("both name and lang provided (please pick one of them!)")
-	} else if lang != "" {
-		filters = append(filters, fmt.Sprintf("%s='%s'", c.LANGUAGE, lang))
-	} else if name != "" {
-		nameFilter := fmt.Sprintf("%s like '%s'", c.NAME, name)
-		nameFilter = strings.Replace(nameFilter, "*", "%", -1)
-		filters = append(filters, nameFilter)
+// Converts the flags assigned to the stats command into a filter
+// that gets every rep from the database that matches them.
+func argsToFilter(cmd *cobra.Command, now time.Time) (db.RepFilter, error) {
+	filter := db.RepFilter{}
+	filter.Name = cmd.Flag(c.NAME).Value.String()
+	if lang := cmd.Flag(c.LANGUAGE).Value.String(); lang != "" {
+		filter.Langs = []string{lang}
 	}
+	filter.HashPrefix = cmd.Flag(c.HASH).Value.String()
 
 	end := cmd.Flag(c.END).Value.String()
 	since := cmd.Flag("since").Value.String()
 	start := cmd.Flag(c.START).Value.String()
 
 	if end != "" && since == "" && start == "" {
-		return "", fmt.Errorf("must define %s if %s is provided", c.START, c.END)
+		return filter, fmt.Errorf("must define %s if %s is provided", c.START, c.END)
 	}
 
 	if since != "" && start != "" {
-		return "", fmt.Errorf("both since and start flags are provided. please use one or the other.")
+		return filter, fmt.Errorf("both since and start flags are provided. please use one or the other.")
 	} else if since != "" && start == "" {
 		start = since
 	}
 
 	startTime, err := parseDateFromArg(false, start, now)
 	if err != nil {
-		return "", fmt.Errorf("failed to parse start flag: %s", err)
+		return filter, fmt.Errorf("failed to parse start flag: %s", err)
 	}
-	filters = append(filters, fmt.Sprintf("%s >= %d", c.START, startTime.UnixMilli()))
+	filter.Start = startTime
 
 	endTime, err := parseDateFromArg(true, end, now)
 	if err != nil {
-		return "", fmt.Errorf("failed to parse end flag: %s", err)
+		return filter, fmt.Errorf("failed to parse end flag: %s", err)
 	}
-	filters = append(filters, fmt.Sprintf("%s <= %d", c.END, endTime.UnixMilli()))
+	filter.End = endTime
 
 	if endTime.Before(startTime) {
-		return "", fmt.Errorf("%s is before %s", c.END, c.START)
+		return filter, fmt.Errorf("%s is before %s", c.END, c.START)
 	}
 
-	query := fmt.Sprintf("select * from reps where %s order by %s;", strings.Join(filters, " and "), c.START)
+	return filter, nil
+}
+
+// Gets the reps that match the filter flags of a command.
+// The command's flags must be set with `SetFilterFlags`.
+func FilteredReps(cmd *cobra.Command, now time.Time) ([]db.Rep, error) {
+	filter, err := argsToFilter(cmd, now)
+	if err != nil {
+		return nil, err
+	}
+	return filterToReps(filter)
+}
+
+// Loads the events of the reps, for views that need every keystroke.
+func loadEvents(reps []db.Rep) error {
+	statsDb, err := db.SweetDb()
+	if err != nil {
+		return fmt.Errorf("failed to connect to database: %s\n", err)
+	}
+	defer statsDb.Close()
 
-	return query, nil
+	if err := db.LoadEvents(statsDb, reps); err != nil {
+		return fmt.Errorf("failed to get events: %s\n", err)
+	}
+	return nil
 }
 
-func queryToReps(query string) (reps []db.Rep, err error) {
+func filterToReps(filter db.RepFilter) (reps []db.Rep, err error) {
 	statsDb, err := db.SweetDb()
 	if err != nil {
 		return nil, fmt.Errorf("failed to connect to database: %s\n", err)
 	}
+	defer statsDb.Close()
 
-	reps, err = db.GetReps(statsDb, query)
+	reps, err = db.GetReps(statsDb, filter)
 
 	if err != nil {
 		return nil, fmt.Errorf("failed to get reps: %s\n", err)
@@ -184,7 +278,15 @@ func queryToReps(query string) (reps []db.Rep, err error) {
 	return
 }
 
-func argsToColumnFilter(cmd *cobra.Command) []string {
+// The columns that are shown if none are selected, and
+// the config file doesn't set any.
+var defaultColumns = []string{
+	c.WPM, c.RAW_WPM, c.ACCURACY, c.UNCORRECTED_ERRORS, c.MISTAKES,
+}
+
+// Returns the columns to show. If no column flags are passed,
+// then the default columns are shown.
+func argsToColumnFilter(cmd *cobra.Command, defaultCols []string) []string {
 	cols := []string{c.START}
 	name := cmd.Flag(c.NAME).Value.String()
 	showName := name == "" || strings.Contains(name, "*")
@@ -206,10 +308,7 @@ func argsToColumnFilter(cmd *cobra.Command) []string {
 	}
 
 	if selectedColCount == 0 {
-		defaultCols := append(cols,
-			c.WPM, c.RAW_WPM, c.ACCURACY, c.UNCORRECTED_ERRORS, c.MISTAKES,
-		)
-		return defaultCols
+		return append(cols, defaultCols...)
 	} else {
 		return cols
 	}
@@ -526,20 +625,76 @@ func renderGraph(cols []string, reps []db.Rep) {
 	fmt.Println(graph)
 }
 
+// The number of characters of a hash that are shown.
+const shortHashLen = 7
+
+// Returns the beginning of a hash, which is usually enough
+// to tell the exercises apart.
+func ShortHash(hash string) string {
+	if len(hash) > shortHashLen {
+		return hash[:shortHashLen]
+	}
+	return hash
+}
+
+// Returns the key that tells an exercise's reps apart from other
+// exercises' reps. Reps are of the same exercise if they share a name
+// and a hash, since files of different names can have the same text.
+// Generated exercises, like drills, get new text every rep, so their
+// reps are of the same exercise if they share a name.
+func ExerciseKey(rep db.Rep) string {
+	if rep.Generated() {
+		return rep.Name
+	}
+	return rep.Name + "\x00" + rep.Hash
+}
+
+// Returns the label of each exercise in the reps, by their
+// `ExerciseKey`. If reps of different hashes share a name, then the
+// exercise was changed between them, so each version of it is labeled
+// with its short hash, like `hello.go (1a2b3c4)`.
+func ExerciseLabels(reps []db.Rep) map[string]string {
+	versions := map[string]map[string]bool{}
+	for _, rep := range reps {
+		if rep.Generated() {
+			continue
+		}
+		if versions[rep.Name] == nil {
+			versions[rep.Name] = map[string]bool{}
+		}
+		versions[rep.Name][rep.Hash] = true
+	}
+	labels := map[string]string{}
+	for _, rep := range reps {
+		label := rep.Name
+		if len(versions[rep.Name]) > 1 {
+			label = fmt.Sprintf("%s (%s)", rep.Name, ShortHash(rep.Hash))
+		}
+		labels[ExerciseKey(rep)] = label
+	}
+	return labels
+}
+
 func renderReps(cols []string, reps []db.Rep) {
+	labels := ExerciseLabels(reps)
 	table := tw.NewWriter(os.Stdout)
 	table.SetHeader(cols)
 	for _, rep := range reps {
 		repCols := []string{}
-		for _, c := range cols {
-			repCols = append(repCols, rep.ColumnString(c))
+		for _, col := range cols {
+			if col == c.NAME {
+				repCols = append(repCols, labels[ExerciseKey(rep)])
+			} else {
+				repCols = append(repCols, rep.ColumnString(col))
+			}
 		}
 		table.Append(repCols)
 	}
 	table.Render()
 }
 
-func render(cmd *cobra.Command, reps []db.Rep) {
+// Renders the header from the filter flags of the command.
+func renderFlagsHeader(cmd *cobra.Command) {
 	name := cmd.Flag(c.NAME).Value.String()
 	lang := cmd.Flag(c.LANGUAGE).Value.String()
 	start := cmd.Flag(c.START).Value.String()
@@ -547,10 +702,19 @@ func render(cmd *cobra.Command, reps []db.Rep) {
 		start = since
 	}
 	end := cmd.Flag(c.END).Value.String()
+	if hash := cmd.Flag(c.HASH).Value.String(); hash != "" {
+		if name == "" {
+			name = "hash " + hash
+		} else {
+			name = fmt.Sprintf("%s (%s)", name, hash)
+		}
+	}
 
 	renderHeader(name, lang, start, end)
+}
 
-	cols := argsToColumnFilter(cmd)
+func render(cmd *cobra.Command, reps []db.Rep, cols []string) {
+	renderFlagsHeader(cmd)
 
 	if len(reps) == 0 {
 		fmt.Println("no stats")
@@ -561,15 +725,37 @@ func render(cmd *cobra.Command, reps []db.Rep) {
 	}
 }
 
-func setStatsCommandFlags(cmd *cobra.Command) {
+// Renders the stats of the reps, with the graph and
+// table showing the buckets the reps are grouped into.
+func renderGrouped(cmd *cobra.Command, reps []db.Rep, cols []string, period string, buckets []bucket) {
+	renderFlagsHeader(cmd)
+
+	if len(reps) == 0 {
+		fmt.Println("no stats")
+	} else {
+		renderStatsTable(cols, reps)
+		renderBucketGraph(buckets)
+		renderBuckets(period, buckets)
+	}
+}
+
+// Sets the flags that choose which reps to get. Other commands
+// that work with the same reps as the stats command use these, too.
+func SetFilterFlags(cmd *cobra.Command) {
 	// date selection flags
 	cmd.Flags().StringP(c.START, "s", "", "find stats starting from this date")
 	cmd.Flags().String("since", "", "alias for \"start\" flag")
 	cmd.Flags().StringP(c.END, "n", "", "find stats ending at this date")
 
-	// column filtering flags
 	cmd.Flags().String(c.NAME, "", "filter by exercise name")
 	cmd.Flags().StringP(c.LANGUAGE, "l", "", "filter by language")
+	cmd.Flags().String(c.HASH, "", "filter by the hash of the exercise's text, or its beginning")
+}
+
+func setStatsCommandFlags(cmd *cobra.Command) {
+	SetFilterFlags(cmd)
+
+	// column filtering flags
 	cmd.Flags().BoolP(c.WPM, "w", false, "show words per minute (wpm)")
 	cmd.Flags().BoolP(c.RAW_WPM, "r", false, "show raw words per minute")
 	cmd.Flags().BoolP(c.ACCURACY, "a", false, "show accuracy (acc)")
@@ -577,6 +763,13 @@ func setStatsCommandFlags(cmd *cobra.Command) {
 	cmd.Flags().BoolP(c.UNCORRECTED_ERRORS, "e", false, "show uncorrected errors")
 	cmd.Flags().BoolP(c.DURATION, "d", false, "show duration")
 
+	cmd.Flags().BoolP("keys", "k", false, "show a heatmap of each key's miss rate and latency")
+	cmd.Flags().BoolP("fingers", "f", false, "show the accuracy and latency of each finger")
+	cmd.Flags().Bool("ngrams", false, "show the slowest and most missed sequences of two and three keys")
+
+	cmd.Flags().String("group-by", "", "graph and list the reps by day, week, or month")
+	cmd.Flags().String("by", "", "compare the reps of each exercise or lang")
+
 	cmd.Flags().SortFlags = false
 }
 
@@ -1,10 +1,14 @@
 package stats
 
 import (
-	"fmt"
+	"slices"
+	"strings"
 	"testing"
 	"time"
 
+	c "github.com/NicksPatties/sweet/constants"
+	"github.com/NicksPatties/sweet/db"
+	"github.com/NicksPatties/sweet/util"
 	"github.com/spf13/cobra"
 )
 
@@ -176,7 +180,7 @@ func TestParseDateFromArg(t *testing.T) {
 	}
 }
 
-func TestArgsToQuery(t *testing.T) {
+func TestArgsToFilter(t *testing.T) {
 	// 2024-12-06 17:36:20.000000 -0700
 	now := time.Date(2024, 12, 6, 17, 36, 20, 0, time.Now().Location())
 	nowAtMidnight := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
@@ -185,24 +189,50 @@ func TestArgsToQuery(t *testing.T) {
 	type testCase struct {
 		name    string
 		in      []string
-		want    string
+		want    db.RepFilter
 		wantErr bool
+		// the pattern the name is matched with in the query, if given
+		wantPattern string
+	}
+
+	matches := func(got db.RepFilter, want db.RepFilter) bool {
+		return got.Name == want.Name &&
+			slices.Equal(got.Langs, want.Langs) &&
+			got.HashPrefix == want.HashPrefix &&
+			got.Start.Equal(want.Start) &&
+			got.End.Equal(want.End)
 	}
 
 	var mockCmd = func(tc testCase) *cobra.Command {
 		cmd := &cobra.Command{
 			Run: func(cmd *cobra.Command, args []string) {
-				got, err := argsToQuery(cmd, now)
+				got, err := argsToFilter(cmd, now)
 				if err == nil && tc.wantErr {
 					t.Errorf("%s wanted error, got nil", tc.name)
 				}
+				if err != nil && !tc.wantErr {
+					t.Errorf("%s wanted no error, got %s", tc.name, err)
+				}
 
-				if got != tc.want {
+				if !tc.wantErr && !matches(got, tc.want) {
 					t.Errorf("%s\n"+
-						"  got:  %s\n"+
-						"  want: %s\n",
+						"  got:  %+v\n"+
+						"  want: %+v\n",
 						tc.name, got, tc.want)
 				}
+
+				if tc.wantPattern != "" {
+					_, args, err := got.Query()
+					if err != nil {
+						t.Fatalf("%s failed to make query: %s", tc.name, err)
+					}
+					if len(args) == 0 || args[0] != tc.wantPattern {
+						t.Errorf("%s\n"+
+							"  got args: %q\n"+
+							"  want pattern: %q\n",
+							tc.name, args, tc.wantPattern)
+					}
+				}
 			},
 		}
 		setStatsCommandFlags(cmd)
@@ -214,95 +244,77 @@ func TestArgsToQuery(t *testing.T) {
 		{
 			name: "default case (get stats from today only)",
 			in:   []string{},
-			want: fmt.Sprintf(
-				"select * from reps where start >= %d and end <= %d order by start;",
-				nowAtMidnight.UnixMilli(),
-				nowBeforeMidnight.UnixMilli(),
-			),
-			wantErr: false,
+			want: db.RepFilter{Start: nowAtMidnight, End: nowBeforeMidnight},
 		},
 		{
 			name: "since is an alias for start",
 			in:   []string{"--since=2D"},
-			want: fmt.Sprintf(
-				"select * from reps where start >= %d and end <= %d order by start;",
-				nowAtMidnight.AddDate(0, 0, -2).UnixMilli(),
-				nowBeforeMidnight.UnixMilli(),
-			),
-			wantErr: false,
+			want: db.RepFilter{Start: nowAtMidnight.AddDate(0, 0, -2), End: nowBeforeMidnight},
 		},
 		{
 			name:    "both since and start are given. error",
 			in:      []string{"--since=2D", "--start=1D"},
-			want:    "",
 			wantErr: true,
 		},
 		{
 			name: "start provided",
 			in:   []string{"--start=1D"},
-			want: fmt.Sprintf(
-				"select * from reps where start >= %d and end <= %d order by start;",
-				nowAtMidnight.AddDate(0, 0, -1).UnixMilli(),
-				nowBeforeMidnight.UnixMilli(),
-			),
-			wantErr: false,
+			want: db.RepFilter{Start: nowAtMidnight.AddDate(0, 0, -1), End: nowBeforeMidnight},
 		},
 		{
 			name:    "end provided, but no start",
 			in:      []string{"--end=1D"},
-			want:    "",
 			wantErr: true,
 		},
 		{
 			name: "start and end provided",
 			in:   []string{"--start=2024-10-01", "--end=2024-11-01"},
-			want: fmt.Sprintf(
-				"select * from reps where start >= %d and end <= %d order by start;",
-				time.Date(2024, time.October, 1, 0, 0, 0, 0, now.Location()).UnixMilli(),
-				time.Date(2024, time.November, 1, 0, 0, 0, 0, now.Location()).AddDate(0, 0, 1).Add(-1*time.Nanosecond).UnixMilli()),
-			wantErr: false,
+			want: db.RepFilter{
+				Start: time.Date(2024, time.October, 1, 0, 0, 0, 0, now.Location()),
+				End:   time.Date(2024, time.November, 1, 0, 0, 0, 0, now.Location()).AddDate(0, 0, 1).Add(-1 * time.Nanosecond),
+			},
 		},
 		{
 			name:    "start and end provided, but end is before start",
 			in:      []string{"--start=1D", "--end=3D"},
-			want:    "",
 			wantErr: true,
 		},
 		{
 			name: "language provided",
 			in:   []string{"--lang=py"},
-			want: fmt.Sprintf(
-				"select * from reps where lang='py' and start >= %d and end <= %d order by start;",
-				nowAtMidnight.UnixMilli(),
-				nowBeforeMidnight.UnixMilli(),
-			),
-			wantErr: false,
+			want: db.RepFilter{Langs: []string{"py"}, Start: nowAtMidnight, End: nowBeforeMidnight},
 		},
 		{
 			name: "name provided",
 			in:   []string{"--name=filename.go"},
-			want: fmt.Sprintf(
-				"select * from reps where name like 'filename.go' and start >= %d and end <= %d order by start;",
-				nowAtMidnight.UnixMilli(),
-				nowBeforeMidnight.UnixMilli(),
-			),
-			wantErr: false,
+			want: db.RepFilter{Name: "filename.go", Start: nowAtMidnight, End: nowBeforeMidnight},
 		},
 		{
-			name: "name with wildcard",
-			in:   []string{"--name=file*"},
-			want: fmt.Sprintf(
-				"select * from reps where name like 'file%%' and start >= %d and end <= %d order by start;",
-				nowAtMidnight.UnixMilli(),
-				nowBeforeMidnight.UnixMilli(),
-			),
-			wantErr: false,
+			name: "name with quotes",
+			in:   []string{"--name=it's.go"},
+			want: db.RepFilter{Name: "it's.go", Start: nowAtMidnight, End: nowBeforeMidnight},
 		},
 		{
-			name:    "both name and language provided, error",
-			in:      []string{"--name=filename.go", "--lang=py"},
-			want:    "",
-			wantErr: true,
+			name:        "name with wildcard",
+			in:          []string{"--name=file*"},
+			want:        db.RepFilter{Name: "file*", Start: nowAtMidnight, End: nowBeforeMidnight},
+			wantPattern: "file%",
+		},
+		{
+			name:        "name with wildcard and like characters",
+			in:          []string{"--name=100%_done*"},
+			want:        db.RepFilter{Name: "100%_done*", Start: nowAtMidnight, End: nowBeforeMidnight},
+			wantPattern: `100\%\_done%`,
+		},
+		{
+			name: "both name and language provided",
+			in:   []string{"--name=file*", "--lang=py"},
+			want: db.RepFilter{Name: "file*", Langs: []string{"py"}, Start: nowAtMidnight, End: nowBeforeMidnight},
+		},
+		{
+			name: "name and hash provided",
+			in:   []string{"--name=hello.go", "--hash=1a2b3c4"},
+			want: db.RepFilter{Name: "hello.go", HashPrefix: "1a2b3c4", Start: nowAtMidnight, End: nowBeforeMidnight},
 		},
 	}
 
@@ -318,15 +330,20 @@ func TestArgsToQuery(t *testing.T) {
 func TestArgsToColumnFilter(t *testing.T) {
 
 	type testCase struct {
-		name string
-		in   []string
-		want []string
+		name        string
+		in          []string
+		defaultCols []string
+		want        []string
 	}
 
 	var mockCmd = func(tc testCase) *cobra.Command {
 		cmd := &cobra.Command{
 			Run: func(cmd *cobra.Command, args []string) {
-				got := argsToColumnFilter(cmd)
+				defaultCols := defaultColumns
+				if tc.defaultCols != nil {
+					defaultCols = tc.defaultCols
+				}
+				got := argsToColumnFilter(cmd, defaultCols)
 				if len(got) != len(tc.want) {
 					t.Fatalf("%s\n"+
 						"  got:  %s\n"+
@@ -382,6 +399,18 @@ func TestArgsToColumnFilter(t *testing.T) {
 			in:   []string{"--raw", "--name=hello.go"},
 			want: []string{"start", "raw"},
 		},
+		{
+			name:        "default columns from the config file",
+			in:          []string{},
+			defaultCols: []string{"wpm", "dur"},
+			want:        []string{"start", "name", "wpm", "dur"},
+		},
+		{
+			name:        "column flags override the config file's default columns",
+			in:          []string{"--acc"},
+			defaultCols: []string{"wpm", "dur"},
+			want:        []string{"start", "name", "acc"},
+		},
 	}
 	for _, tc := range testCases {
 		cmd := mockCmd(tc)
@@ -391,3 +420,34 @@ func TestArgsToColumnFilter(t *testing.T) {
 		}
 	}
 }
+
+func TestExerciseLabels(t *testing.T) {
+	reps := []db.Rep{
+		{Hash: "1a2b3c4d5e", Name: "hello.go"},
+		{Hash: "f0e1d2c3b4", Name: "hello.go"},
+		{Hash: "0123456789", Name: "world.go"},
+		// same text as world.go, but a different file
+		{Hash: "0123456789", Name: "copy.go"},
+	}
+	labels := ExerciseLabels(reps)
+	want := []string{"hello.go (1a2b3c4)", "hello.go (f0e1d2c)", "world.go", "copy.go"}
+	for i, rep := range reps {
+		if got := labels[ExerciseKey(rep)]; got != want[i] {
+			t.Errorf("rep %d: got label %q, want %q", i, got, want[i])
+		}
+	}
+}
+
+func TestRenderReps_sameText(t *testing.T) {
+	start := time.Date(2024, 10, 7, 13, 0, 0, 0, time.UTC)
+	reps := []db.Rep{
+		{Hash: "0123456789", Name: "a.go", Start: start},
+		{Hash: "0123456789", Name: "b.go", Start: start.Add(time.Minute)},
+	}
+	got := util.GetStringFromStdout(func() {
+		renderReps([]string{c.NAME}, reps)
+	})
+	if strings.Count(got, "a.go") != 1 || strings.Count(got, "b.go") != 1 {
+		t.Errorf("files with the same text should keep their names, got:\n%s", got)
+	}
+}
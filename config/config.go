@@ -0,0 +1,387 @@
+// Reads and writes sweet's configuration file.
+//
+// The configuration file sets the default values of sweet's flags.
+// It's a TOML file named `config.toml` in sweet's configuration
+// directory (see `util.SweetConfigDir`). Values are chosen in this
+// order: flags, then environment variables, then the configuration
+// file, and then sweet's defaults.
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+
+	c "github.com/NicksPatties/sweet/constants"
+	"github.com/NicksPatties/sweet/keyboard"
+	"github.com/NicksPatties/sweet/util"
+
+	"github.com/BurntSushi/toml"
+)
+
+// The colors of one of the exercise's styles. Colors are either
+// ANSI color numbers, i.e. "1", or hex colors, i.e. "#ff0000".
+// Empty colors use the style's default color.
+type Style struct {
+	Foreground string `toml:"foreground,omitempty"`
+	Background string `toml:"background,omitempty"`
+}
+
+type Config struct {
+	// The number of visible lines in an exercise.
+	WindowSize uint `toml:"window-size,omitempty"`
+
+	// The keyboard layout shown while typing.
+	Layout string `toml:"layout,omitempty"`
+
+	// The directory exercises are chosen from.
+	ExercisesDir string `toml:"exercises-dir,omitempty"`
+
+	// The directory of the stats database.
+	DbLocation string `toml:"db-location,omitempty"`
+
+	// The columns `sweet stats` shows if none are chosen.
+	StatsColumns []string `toml:"stats-columns,omitempty"`
+
+	// The name of the color theme.
+	ThemeName string `toml:"theme,omitempty"`
+
+	// The user's own themes, by the theme's name.
+	Themes map[string]Theme `toml:"themes,omitempty"`
+
+	// The colors of the exercise's styles, by the style's name.
+	// These replace the colors of the theme.
+	Styles map[string]Style `toml:"styles,omitempty"`
+}
+
+// The names of the styles that can be set in the config file.
+var StyleNames = []string{
+	"comment", "untyped", "cursor", "typed", "mistake", "vignette", "ghost",
+	"keyword", "string", "number", "punctuation", "keyboard",
+}
+
+// The columns that `sweet stats` can show by default.
+var StatsColumns = []string{
+	c.WPM, c.RAW_WPM, c.ACCURACY, c.UNCORRECTED_ERRORS, c.MISTAKES, c.DURATION,
+}
+
+// A value in the config file that can be read and written
+// with `sweet config`.
+type setting struct {
+	key string
+	get func(conf Config) string
+	set func(conf *Config, value string) error
+}
+
+func settings() []setting {
+	s := []setting{
+		{
+			key: "window-size",
+			get: func(conf Config) string {
+				if conf.WindowSize == 0 {
+					return ""
+				}
+				return strconv.FormatUint(uint64(conf.WindowSize), 10)
+			},
+			set: func(conf *Config, value string) error {
+				if value == "" {
+					conf.WindowSize = 0
+					return nil
+				}
+				size, err := strconv.ParseUint(value, 10, 32)
+				if err != nil {
+					return fmt.Errorf("window-size must be a positive number: %s", value)
+				}
+				conf.WindowSize = uint(size)
+				return nil
+			},
+		},
+		{
+			key: "layout",
+			get: func(conf Config) string { return conf.Layout },
+			set: func(conf *Config, value string) error {
+				if value != "" {
+					if _, err := keyboard.Get(value); err != nil {
+						return err
+					}
+				}
+				conf.Layout = value
+				return nil
+			},
+		},
+		{
+			key: "theme",
+			get: func(conf Config) string { return conf.ThemeName },
+			set: func(conf *Config, value string) error {
+				if value != "" {
+					if _, err := conf.GetTheme(value); err != nil {
+						return err
+					}
+				}
+				conf.ThemeName = value
+				return nil
+			},
+		},
+		{
+			key: "exercises-dir",
+			get: func(conf Config) string { return conf.ExercisesDir },
+			set: func(conf *Config, value string) error {
+				conf.ExercisesDir = value
+				return nil
+			},
+		},
+		{
+			key: "db-location",
+			get: func(conf Config) string { return conf.DbLocation },
+			set: func(conf *Config, value string) error {
+				conf.DbLocation = value
+				return nil
+			},
+		},
+		{
+			key: "stats-columns",
+			get: func(conf Config) string { return strings.Join(conf.StatsColumns, ",") },
+			set: func(conf *Config, value string) error {
+				conf.StatsColumns = nil
+				if value == "" {
+					return nil
+				}
+				for _, col := range strings.Split(value, ",") {
+					col = strings.TrimSpace(col)
+					if !contains(StatsColumns, col) {
+						return fmt.Errorf("unknown stats column %q, must be one of: %s",
+							col, strings.Join(StatsColumns, ", "))
+					}
+					conf.StatsColumns = append(conf.StatsColumns, col)
+				}
+				return nil
+			},
+		},
+	}
+	for _, name := range StyleNames {
+		s = append(s, styleSettings("styles", name,
+			func(conf Config) map[string]Style { return conf.Styles },
+			func(conf *Config) map[string]Style {
+				if conf.Styles == nil {
+					conf.Styles = map[string]Style{}
+				}
+				return conf.Styles
+			},
+		)...)
+	}
+	return s
+}
+
+// Returns the settings for the foreground and background
+// of a style. The read function returns the map the style is
+// in, and the write function does the same, but creates the
+// map if it doesn't exist yet.
+func styleSettings(
+	prefix string,
+	name string,
+	read func(conf Config) map[string]Style,
+	write func(conf *Config) map[string]Style,
+) []setting {
+	get := func(conf Config) Style {
+		return read(conf)[name]
+	}
+	// Empty styles are removed, so they aren't
+	// written to the config file.
+	set := func(conf *Config, style Style) {
+		m := write(conf)
+		m[name] = style
+		if style == (Style{}) {
+			delete(m, name)
+		}
+	}
+	return []setting{
+		{
+			key: fmt.Sprintf("%s.%s.foreground", prefix, name),
+			get: func(conf Config) string { return get(conf).Foreground },
+			set: func(conf *Config, value string) error {
+				style := get(*conf)
+				style.Foreground = value
+				set(conf, style)
+				return nil
+			},
+		},
+		{
+			key: fmt.Sprintf("%s.%s.background", prefix, name),
+			get: func(conf Config) string { return get(conf).Background },
+			set: func(conf *Config, value string) error {
+				style := get(*conf)
+				style.Background = value
+				set(conf, style)
+				return nil
+			},
+		},
+	}
+}
+
+// Returns the settings for the styles of one of the user's themes.
+func themeSettings(theme string) (s []setting) {
+	for _, name := range StyleNames {
+		s = append(s, styleSettings("themes."+theme, name,
+			func(conf Config) map[string]Style { return conf.Themes[theme] },
+			func(conf *Config) map[string]Style {
+				if conf.Themes == nil {
+					conf.Themes = map[string]Theme{}
+				}
+				if conf.Themes[theme] == nil {
+					conf.Themes[theme] = Theme{}
+				}
+				return conf.Themes[theme]
+			},
+		)...)
+	}
+	return
+}
+
+func contains(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
+
+func findSetting(key string) (setting, error) {
+	all := settings()
+	// Keys of the user's themes look like
+	// `themes.[theme].[style].[foreground|background]`.
+	if parts := strings.Split(key, "."); len(parts) == 4 && parts[0] == "themes" {
+		all = themeSettings(parts[1])
+	}
+	for _, s := range all {
+		if s.key == key {
+			return s, nil
+		}
+	}
+	return setting{}, fmt.Errorf("unknown config key %q, run `sweet config list` to see every key", key)
+}
+
+// Returns every key that can be set in the config file,
+// including the keys of the user's themes.
+func (conf Config) Keys() (keys []string) {
+	all := settings()
+	themes := []string{}
+	for theme := range conf.Themes {
+		themes = append(themes, theme)
+	}
+	sort.Strings(themes)
+	for _, theme := range themes {
+		all = append(all, themeSettings(theme)...)
+	}
+	for _, s := range all {
+		keys = append(keys, s.key)
+	}
+	return
+}
+
+// Gets the value of a key as a string. Values that
+// aren't set are empty strings.
+func (conf Config) Get(key string) (string, error) {
+	s, err := findSetting(key)
+	if err != nil {
+		return "", err
+	}
+	return s.get(conf), nil
+}
+
+// Sets the value of a key from a string. An empty
+// string unsets the value.
+func (conf *Config) Set(key string, value string) error {
+	s, err := findSetting(key)
+	if err != nil {
+		return err
+	}
+	return s.set(conf, value)
+}
+
+// Gets the path of the config file.
+func Path() (string, error) {
+	configDir, err := util.SweetConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return path.Join(configDir, "config.toml"), nil
+}
+
+// Reads the config file. If the file doesn't exist, then
+// an empty config is returned.
+func Load() (conf Config, err error) {
+	configPath, err := Path()
+	if err != nil {
+		return
+	}
+	_, err = toml.DecodeFile(configPath, &conf)
+	if errors.Is(err, os.ErrNotExist) {
+		return Config{}, nil
+	}
+	if err != nil {
+		return Config{}, fmt.Errorf("failed to read config file %s: %v", configPath, err)
+	}
+	return
+}
+
+// Writes the config to the config file, creating the
+// file and its directory if they don't exist.
+func Save(conf Config) error {
+	configPath, err := Path()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(path.Dir(configPath), 0775); err != nil {
+		return fmt.Errorf("failed to create config directory: %v", err)
+	}
+	f, err := os.Create(configPath)
+	if err != nil {
+		return fmt.Errorf("failed to write config file: %v", err)
+	}
+	defer f.Close()
+	return toml.NewEncoder(f).Encode(conf)
+}
+
+// Gets the path to the exercises directory. This is the path
+// specified by `SWEET_EXERCISES_DIR`, then the config file's
+// `exercises-dir`, and then the `exercises` directory in
+// sweet's configuration directory.
+func ExercisesDir() (string, error) {
+	if envDir := os.Getenv("SWEET_EXERCISES_DIR"); envDir != "" {
+		return envDir, nil
+	}
+	conf, err := Load()
+	if err != nil {
+		return "", err
+	}
+	if conf.ExercisesDir != "" {
+		return conf.ExercisesDir, nil
+	}
+	sweetConfigDir, err := util.SweetConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return path.Join(sweetConfigDir, "exercises"), nil
+}
+
+// Gets the directory of the stats database. This is the path
+// specified by `SWEET_DB_LOCATION`, then the config file's
+// `db-location`, and then sweet's configuration directory.
+func DbLocation() (string, error) {
+	if envDir := os.Getenv("SWEET_DB_LOCATION"); envDir != "" {
+		return envDir, nil
+	}
+	conf, err := Load()
+	if err != nil {
+		return "", err
+	}
+	if conf.DbLocation != "" {
+		return conf.DbLocation, nil
+	}
+	return util.SweetConfigDir()
+}
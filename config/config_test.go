@@ -0,0 +1,148 @@
+package config
+
+import (
+	"os"
+	"path"
+	"testing"
+)
+
+func TestLoad(t *testing.T) {
+	t.Run("missing config file", func(t *testing.T) {
+		t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+		conf, err := Load()
+		if err != nil {
+			t.Fatalf("should not error: %v", err)
+		}
+		if conf.WindowSize != 0 || conf.Layout != "" || len(conf.Styles) != 0 {
+			t.Errorf("should be empty, got %v", conf)
+		}
+	})
+
+	t.Run("invalid config file", func(t *testing.T) {
+		configHome := t.TempDir()
+		t.Setenv("XDG_CONFIG_HOME", configHome)
+		os.MkdirAll(path.Join(configHome, "sweet"), 0775)
+		os.WriteFile(path.Join(configHome, "sweet", "config.toml"), []byte("window-size = ["), 0664)
+		if _, err := Load(); err == nil {
+			t.Errorf("wanted error, got nil")
+		}
+	})
+
+	t.Run("save and load", func(t *testing.T) {
+		configHome := t.TempDir()
+		t.Setenv("XDG_CONFIG_HOME", configHome)
+		conf := Config{}
+		values := map[string]string{
+			"window-size":              "5",
+			"layout":                   "dvorak",
+			"stats-columns":            "wpm,dur",
+			"styles.cursor.background": "#ff0000",
+		}
+		for key, value := range values {
+			if err := conf.Set(key, value); err != nil {
+				t.Fatalf("failed to set %s: %v", key, err)
+			}
+		}
+		if err := Save(conf); err != nil {
+			t.Fatalf("failed to save: %v", err)
+		}
+
+		got, err := Load()
+		if err != nil {
+			t.Fatalf("failed to load: %v", err)
+		}
+		for key, want := range values {
+			value, err := got.Get(key)
+			if err != nil {
+				t.Fatalf("failed to get %s: %v", key, err)
+			}
+			if value != want {
+				t.Errorf("%s: got %q, want %q", key, value, want)
+			}
+		}
+	})
+}
+
+func TestSet(t *testing.T) {
+	testCases := []struct {
+		name    string
+		key     string
+		value   string
+		wantErr bool
+	}{
+		{name: "unknown key", key: "colour", value: "red", wantErr: true},
+		{name: "window size isn't a number", key: "window-size", value: "big", wantErr: true},
+		{name: "negative window size", key: "window-size", value: "-1", wantErr: true},
+		{name: "unknown layout", key: "layout", value: "azerty", wantErr: true},
+		{name: "unknown stats column", key: "stats-columns", value: "wpm,events", wantErr: true},
+		{name: "unknown style", key: "styles.cursive.foreground", value: "1", wantErr: true},
+		{name: "unset a value", key: "layout", value: "", wantErr: false},
+		{name: "style color", key: "styles.typed.foreground", value: "15", wantErr: false},
+	}
+
+	for _, tc := range testCases {
+		conf := Config{}
+		err := conf.Set(tc.key, tc.value)
+		if tc.wantErr && err == nil {
+			t.Errorf("%s: wanted error, got nil", tc.name)
+		}
+		if !tc.wantErr && err != nil {
+			t.Errorf("%s: wanted no error, got %v", tc.name, err)
+		}
+	}
+
+	t.Run("unsetting a style's colors removes the style", func(t *testing.T) {
+		conf := Config{}
+		conf.Set("styles.typed.foreground", "15")
+		conf.Set("styles.typed.foreground", "")
+		if _, ok := conf.Styles["typed"]; ok {
+			t.Errorf("got styles %v, want no typed style", conf.Styles)
+		}
+	})
+}
+
+func TestExercisesDir(t *testing.T) {
+	configHome := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", configHome)
+	t.Setenv("SWEET_EXERCISES_DIR", "")
+
+	got, _ := ExercisesDir()
+	if want := path.Join(configHome, "sweet", "exercises"); got != want {
+		t.Errorf("default: got %s, want %s", got, want)
+	}
+
+	Save(Config{ExercisesDir: "/from/config"})
+	got, _ = ExercisesDir()
+	if want := "/from/config"; got != want {
+		t.Errorf("config file: got %s, want %s", got, want)
+	}
+
+	t.Setenv("SWEET_EXERCISES_DIR", "/from/env")
+	got, _ = ExercisesDir()
+	if want := "/from/env"; got != want {
+		t.Errorf("environment variable: got %s, want %s", got, want)
+	}
+}
+
+func TestDbLocation(t *testing.T) {
+	configHome := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", configHome)
+	t.Setenv("SWEET_DB_LOCATION", "")
+
+	got, _ := DbLocation()
+	if want := path.Join(configHome, "sweet"); got != want {
+		t.Errorf("default: got %s, want %s", got, want)
+	}
+
+	Save(Config{DbLocation: "/from/config"})
+	got, _ = DbLocation()
+	if want := "/from/config"; got != want {
+		t.Errorf("config file: got %s, want %s", got, want)
+	}
+
+	t.Setenv("SWEET_DB_LOCATION", "/from/env")
+	got, _ = DbLocation()
+	if want := "/from/env"; got != want {
+		t.Errorf("environment variable: got %s, want %s", got, want)
+	}
+}
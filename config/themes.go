@@ -0,0 +1,140 @@
+package config
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// A color theme. Maps the name of each style to its colors.
+// Styles that a theme doesn't set use the default theme's colors.
+type Theme map[string]Style
+
+// The theme that's used if the user doesn't choose one.
+const DefaultTheme = "default"
+
+// Themes that come with sweet. Users can define their own themes
+// in the config file, which replace built-in themes of the same name.
+var Themes = map[string]Theme{
+	DefaultTheme: {
+		"comment":     {Foreground: "7"},
+		"untyped":     {Foreground: "7"},
+		"cursor":      {Foreground: "0", Background: "15"},
+		"typed":       {Foreground: "15"},
+		"mistake":     {Foreground: "15", Background: "1"},
+		"vignette":    {Foreground: "8"},
+		"ghost":       {Foreground: "15", Background: "8"},
+		"keyword":     {Foreground: "5"},
+		"string":      {Foreground: "2"},
+		"number":      {Foreground: "6"},
+		"punctuation": {Foreground: "4"},
+		// Empty colors reverse the key's colors.
+		"keyboard": {},
+	},
+	// For terminals with light backgrounds.
+	"light": {
+		"comment":     {Foreground: "8"},
+		"untyped":     {Foreground: "8"},
+		"cursor":      {Foreground: "15", Background: "0"},
+		"typed":       {Foreground: "0"},
+		"mistake":     {Foreground: "15", Background: "1"},
+		"vignette":    {Foreground: "7"},
+		"ghost":       {Foreground: "0", Background: "7"},
+		"keyword":     {Foreground: "#8700af"},
+		"string":      {Foreground: "#005f00"},
+		"number":      {Foreground: "#005f87"},
+		"punctuation": {Foreground: "#0000af"},
+		"keyboard":    {Foreground: "15", Background: "0"},
+	},
+	"solarized-dark": {
+		"comment":     {Foreground: "#586e75"},
+		"untyped":     {Foreground: "#586e75"},
+		"cursor":      {Foreground: "#002b36", Background: "#93a1a1"},
+		"typed":       {Foreground: "#93a1a1"},
+		"mistake":     {Foreground: "#fdf6e3", Background: "#dc322f"},
+		"vignette":    {Foreground: "#073642"},
+		"ghost":       {Foreground: "#fdf6e3", Background: "#586e75"},
+		"keyword":     {Foreground: "#859900"},
+		"string":      {Foreground: "#2aa198"},
+		"number":      {Foreground: "#d33682"},
+		"punctuation": {Foreground: "#268bd2"},
+		"keyboard":    {Foreground: "#fdf6e3", Background: "#268bd2"},
+	},
+	"solarized-light": {
+		"comment":     {Foreground: "#93a1a1"},
+		"untyped":     {Foreground: "#93a1a1"},
+		"cursor":      {Foreground: "#fdf6e3", Background: "#586e75"},
+		"typed":       {Foreground: "#073642"},
+		"mistake":     {Foreground: "#fdf6e3", Background: "#dc322f"},
+		"vignette":    {Foreground: "#eee8d5"},
+		"ghost":       {Foreground: "#fdf6e3", Background: "#93a1a1"},
+		"keyword":     {Foreground: "#859900"},
+		"string":      {Foreground: "#2aa198"},
+		"number":      {Foreground: "#d33682"},
+		"punctuation": {Foreground: "#268bd2"},
+		"keyboard":    {Foreground: "#fdf6e3", Background: "#268bd2"},
+	},
+}
+
+// Returns a copy of the theme, with the colors of the
+// other theme replacing its own.
+func (t Theme) merge(other Theme) Theme {
+	merged := Theme{}
+	for name, style := range t {
+		merged[name] = style
+	}
+	for name, style := range other {
+		s := merged[name]
+		if style.Foreground != "" {
+			s.Foreground = style.Foreground
+		}
+		if style.Background != "" {
+			s.Background = style.Background
+		}
+		merged[name] = s
+	}
+	return merged
+}
+
+// Returns the names of the built-in themes and the
+// themes in the config, sorted alphabetically.
+func (conf Config) ThemeNames() (names []string) {
+	for name := range Themes {
+		names = append(names, name)
+	}
+	for name := range conf.Themes {
+		if _, ok := Themes[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return
+}
+
+// Gets a theme by its name. The colors of the default theme are
+// used for any styles the theme doesn't set.
+func (conf Config) GetTheme(name string) (Theme, error) {
+	theme, ok := conf.Themes[name]
+	if !ok {
+		theme, ok = Themes[name]
+	}
+	if !ok {
+		return nil, fmt.Errorf("unknown theme %q, must be one of: %s",
+			name, strings.Join(conf.ThemeNames(), ", "))
+	}
+	return Themes[DefaultTheme].merge(theme), nil
+}
+
+// Returns the config's theme, with the config's styles
+// replacing the theme's colors.
+func (conf Config) Theme() (Theme, error) {
+	name := conf.ThemeName
+	if name == "" {
+		name = DefaultTheme
+	}
+	theme, err := conf.GetTheme(name)
+	if err != nil {
+		return nil, err
+	}
+	return theme.merge(conf.Styles), nil
+}
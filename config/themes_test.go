@@ -0,0 +1,79 @@
+package config
+
+import (
+	"testing"
+)
+
+func TestBuiltInThemes(t *testing.T) {
+	for name, theme := range Themes {
+		for _, style := range StyleNames {
+			if _, ok := theme[style]; !ok {
+				t.Errorf("%s theme is missing the %s style", name, style)
+			}
+		}
+	}
+}
+
+func TestTheme(t *testing.T) {
+	conf := Config{
+		ThemeName: "mine",
+		Themes: map[string]Theme{
+			"mine":  {"cursor": {Background: "#123456"}},
+			"light": {"typed": {Foreground: "4"}},
+		},
+		Styles: map[string]Style{"typed": {Foreground: "2"}},
+	}
+
+	theme, err := conf.Theme()
+	if err != nil {
+		t.Fatalf("failed to get theme: %v", err)
+	}
+	if got := theme["cursor"]; got.Background != "#123456" || got.Foreground != "0" {
+		t.Errorf("cursor should use the theme's background and the default foreground, got %v", got)
+	}
+	if got := theme["typed"].Foreground; got != "2" {
+		t.Errorf("styles should replace the theme's colors, got %s", got)
+	}
+
+	light, err := conf.GetTheme("light")
+	if err != nil {
+		t.Fatalf("failed to get light theme: %v", err)
+	}
+	if got := light["typed"].Foreground; got != "4" {
+		t.Errorf("user themes should replace built-in themes, got %s", got)
+	}
+
+	if _, err := conf.GetTheme("unknown"); err == nil {
+		t.Errorf("wanted error for an unknown theme, got nil")
+	}
+
+	defaultTheme, err := Config{}.Theme()
+	if err != nil {
+		t.Fatalf("failed to get default theme: %v", err)
+	}
+	if got := defaultTheme["mistake"]; got != Themes[DefaultTheme]["mistake"] {
+		t.Errorf("default theme should be used if none is set, got %v", got)
+	}
+}
+
+func TestSetTheme(t *testing.T) {
+	conf := Config{}
+	if err := conf.Set("theme", "nope"); err == nil {
+		t.Errorf("wanted error for an unknown theme, got nil")
+	}
+	if err := conf.Set("themes.nope.cursor.foreground", "1"); err != nil {
+		t.Fatalf("failed to set a theme's color: %v", err)
+	}
+	if err := conf.Set("theme", "nope"); err != nil {
+		t.Errorf("should be able to use the user's own theme: %v", err)
+	}
+	if got, _ := conf.Get("themes.nope.cursor.foreground"); got != "1" {
+		t.Errorf("got %q, want %q", got, "1")
+	}
+	if got, _ := conf.Get("themes.other.cursor.foreground"); got != "" || conf.Themes["other"] != nil {
+		t.Errorf("getting a missing theme shouldn't create it")
+	}
+	if err := conf.Set("themes.nope.cursive.foreground", "1"); err == nil {
+		t.Errorf("wanted error for an unknown style, got nil")
+	}
+}
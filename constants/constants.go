@@ -9,6 +9,14 @@ const (
 	Arrow   = `↲`
 )
 
+// Names of exercises whose text is made for each rep, like drills
+// and piped input. Their reps share a name, but not their text.
+// Piped input is named after /dev/stdin, the file it's read from.
+const (
+	DRILL_NAME = "drill"
+	STDIN_NAME = "stdin"
+)
+
 // Used for words per minute (WPM) calculations.
 const WORD_SIZE = 5
 
@@ -28,4 +36,24 @@ const (
 	MISTAKES           string = "miss"
 	UNCORRECTED_ERRORS string = "errs"
 	EVENTS             string = "events"
+	TIME_LIMIT         string = "lim"
+	STRICT             string = "strict"
+	PAUSES             string = "pauses"
+)
+
+// Events database table column names.
+// The events table is named EVENTS, like the flag that exports them.
+const (
+	REP_ID   string = "rep_id"
+	SEQUENCE string = "seq"
+	TS       string = "ts"
+	INDEX    string = "idx"
+	TYPED    string = "typed"
+	EXPECTED string = "expected"
+)
+
+// Exercises database table column names.
+// The exercises table also has the HASH and NAME columns.
+const (
+	TEXT string = "text"
 )
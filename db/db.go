@@ -6,11 +6,12 @@ import (
 	"os"
 	"path"
 	"strconv"
+	"strings"
 	"time"
 
+	"github.com/NicksPatties/sweet/config"
 	"github.com/NicksPatties/sweet/constants"
 	"github.com/NicksPatties/sweet/event"
-	"github.com/NicksPatties/sweet/util"
 
 	_ "modernc.org/sqlite"
 )
@@ -34,7 +35,10 @@ type Rep struct {
 	Acc    float64
 	Miss   int
 	Errs   int
-	Events event.Events // one string is one event
+	Events event.Events  // not loaded by GetReps, see LoadEvents
+	Lim    time.Duration // time limit of a timed rep, 0 if untimed
+	Strict bool          // true if mistakes had to be corrected before moving on
+	Pauses event.Pauses  // periods the rep was paused, left out of dur and wpm
 }
 
 func (r Rep) String() (s string) {
@@ -50,9 +54,20 @@ func (r Rep) String() (s string) {
 	s += fmt.Sprintf("  miss:  %d\n", r.Miss)
 	s += fmt.Sprintf("  errs:  %d\n", r.Errs)
 	s += fmt.Sprintf("  events: %d events\n", len(r.Events))
+	s += fmt.Sprintf("  lim:   %s\n", r.Lim)
+	s += fmt.Sprintf("  strict: %t\n", r.Strict)
+	s += fmt.Sprintf("  pauses: %d pauses\n", len(r.Pauses))
 	return
 }
 
+// Returns true if the rep's text was made for it, like a drill or
+// piped input. Reps of these exercises have different hashes, even
+// though they're of the same exercise, so their hashes shouldn't be
+// used to tell versions of the exercise apart.
+func (r Rep) Generated() bool {
+	return r.Name == constants.DRILL_NAME || r.Name == constants.STDIN_NAME
+}
+
 // Returns a string with a decorated version of the asked
 // column corresponding to the matching property of a Rep struct.
 //
@@ -86,6 +101,12 @@ func (r Rep) ColumnString(col string) string {
 		return strconv.Itoa(r.Errs)
 	case constants.EVENTS:
 		return fmt.Sprintf("%s", r.Events)
+	case constants.TIME_LIMIT:
+		return r.Lim.String()
+	case constants.STRICT:
+		return strconv.FormatBool(r.Strict)
+	case constants.PAUSES:
+		return pausesToColumn(r.Pauses)
 	default:
 		return ""
 	}
@@ -94,21 +115,16 @@ func (r Rep) ColumnString(col string) string {
 // Gets a pointer to the stats database. If the database file
 // doesn't exist already, it will be created at sweet's default
 // configuration location (`~/.config/sweet`), or at the path
-// specified by `SWEET_DB_LOCATION`, if it's defined.
+// specified by `SWEET_DB_LOCATION` or the config file's
+// `db-location`, if either is defined.
 //
 // If an error is returned from this function, then the pointer
 // will be `nil`.
 func SweetDb() (*sql.DB, error) {
 	// get the path for the database
-	var dbPath string
-	if envDir := os.Getenv("SWEET_DB_LOCATION"); envDir != "" {
-		dbPath = envDir
-	} else {
-		sweetDir, err := util.SweetConfigDir()
-		if err != nil {
-			return nil, fmt.Errorf("failed to find user config directory: %v", err)
-		}
-		dbPath = sweetDir
+	dbPath, err := config.DbLocation()
+	if err != nil {
+		return nil, fmt.Errorf("failed to find the database location: %v", err)
 	}
 
 	// create the sweet config directory
@@ -116,8 +132,18 @@ func SweetDb() (*sql.DB, error) {
 		return nil, fmt.Errorf("failed to find or create sweet config directory: %v", err)
 	}
 
+	return Open(path.Join(dbPath, "sweet.db"))
+}
+
+// Opens the sweet database at the given file, creating it if it
+// doesn't exist. Databases made by older versions of sweet are
+// updated by running the migrations in `db/migrate.go`.
+//
+// If an error is returned from this function, then the pointer
+// will be `nil`.
+func Open(file string) (*sql.DB, error) {
 	// Open a connection to the SQLite database
-	db, err := sql.Open("sqlite", path.Join(dbPath, "sweet.db"))
+	db, err := sql.Open("sqlite", file)
 	if err != nil {
 		db.Close()
 		return nil, fmt.Errorf("failed to open database: %v", err)
@@ -130,53 +156,18 @@ func SweetDb() (*sql.DB, error) {
 		return nil, fmt.Errorf("failed to connect to database: %v", err)
 	}
 
-	// create the reps table if it doesn't exist
-	createTableStr := fmt.Sprintf(`
-CREATE TABLE if not exists reps(
-  %s integer primary key autoincrement not null,
-  -- md5 hash of the exercise file's contents
-  %s string NOT NULL,
-  -- start time in unix milliseconds
-  %s integer not null,
-  -- end time in unix milliseconds
-  %s integer not null,
-  -- name of the exercise file, includes extension if present
-  %s text not null,
-  -- language: extension of the exercise file, or "" if there is none.
-  %s text,
-  -- words per minute
-  %s real not null check(wpm >= 0.0),
-  -- raw words per minute
-  %s real not null check(raw >= 0.0),
-  -- duration: duration of rep in **nanoseconds**
-  %s integer not null check(dur >= 0),
-  -- accuracy: float between [0, 100]
-  %s real not null check(acc >= 0.0),
-  -- mistakes: must be gte 0
-  %s integer not null check(miss >= 0),
-  -- uncorrected errors: must be gte 0
-  %s integer not null check(errs >= 0),
-  -- array of events, events are separated by '\n'
-  %s text not null
-);`,
-		constants.ID, constants.HASH, constants.START, constants.END, constants.NAME, constants.LANGUAGE, constants.WPM, constants.RAW_WPM,
-		constants.DURATION, constants.ACCURACY, constants.MISTAKES, constants.UNCORRECTED_ERRORS, constants.EVENTS,
-	)
-
-	_, err = db.Exec(createTableStr)
-
-	if err != nil {
+	if err := migrate(db); err != nil {
 		db.Close()
-		return nil, fmt.Errorf("failed to create table: %v", err)
+		return nil, fmt.Errorf("failed to update database: %v", err)
 	}
 
 	return db, nil
 }
 
-func eventsStringToColumn(events []event.Event) (s string) {
-	for i, event := range events {
-		s += event.String()
-		if i != len(events)-1 {
+func pausesToColumn(pauses event.Pauses) (s string) {
+	for i, pause := range pauses {
+		s += pause.String()
+		if i != len(pauses)-1 {
 			s += "\n"
 		}
 	}
@@ -198,41 +189,243 @@ func InsertRep(db *sql.DB, rep Rep) (int64, error) {
 	acc := rep.Acc
 	miss := rep.Miss
 	errs := rep.Errs
-	events := eventsStringToColumn(rep.Events)
+	lim := rep.Lim
+	strict := rep.Strict
+	pauses := pausesToColumn(rep.Pauses)
 	query := fmt.Sprintf(`insert into reps (
 	    %s, %s, %s, %s, %s, %s,
-	    %s, %s, %s, %s, %s, %s
+	    %s, %s, %s, %s, %s, %s,
+	    %s, %s
 	   ) values (
 	   	?, ?, ?, ?, ?, ?,
-	   	?, ?, ?, ?, ?, ?
+	   	?, ?, ?, ?, ?, ?,
+	   	?, ?
 	   );`,
 		constants.HASH, constants.START, constants.END, constants.NAME, constants.LANGUAGE, constants.WPM, constants.RAW_WPM,
-		constants.DURATION, constants.ACCURACY, constants.MISTAKES, constants.UNCORRECTED_ERRORS, constants.EVENTS,
+		constants.DURATION, constants.ACCURACY, constants.MISTAKES, constants.UNCORRECTED_ERRORS,
+		constants.TIME_LIMIT, constants.STRICT, constants.PAUSES,
 	)
 
-	result, err := db.Exec(query,
+	// The rep and its events are saved together, or not at all.
+	tx, err := db.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	result, err := tx.Exec(query,
 		hash, start, end, name, lang, wpm,
-		raw, dur, acc, miss, errs, events,
+		raw, dur, acc, miss, errs,
+		lim, strict, pauses,
 	)
+	if err != nil {
+		return 0, err
+	}
 
+	id, err := result.LastInsertId()
 	if err != nil {
 		return 0, err
 	}
 
-	return result.LastInsertId()
+	if err := insertEvents(tx, int(id), rep.Events); err != nil {
+		return 0, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, err
+	}
+
+	return id, nil
+}
+
+// Inserts the events of the rep with the given id.
+func insertEvents(tx *sql.Tx, repId int, events event.Events) error {
+	if len(events) == 0 {
+		return nil
+	}
+	stmt, err := tx.Prepare(fmt.Sprintf("insert into %s (%s, %s, %s, %s, %s, %s) values (?, ?, ?, ?, ?, ?);",
+		constants.EVENTS, constants.REP_ID, constants.SEQUENCE, constants.TS, constants.INDEX, constants.TYPED, constants.EXPECTED))
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	for seq, e := range events {
+		if _, err := stmt.Exec(repId, seq, e.Ts.UnixMilli(), e.I, e.Typed, e.Expected); err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
-// This should accept an array of columns to show, a start and an end range,
-// and return an array of anything
-func GetReps(db *sql.DB, query string) ([]Rep, error) {
-	if query == "" {
-		query = fmt.Sprintf(`select * from reps order by %s;`, constants.START)
+// Gets the events of the rep with the given id, in
+// the order they happened.
+func GetEvents(db *sql.DB, repId int) (event.Events, error) {
+	reps := []Rep{{Id: repId}}
+	if err := LoadEvents(db, reps); err != nil {
+		return nil, err
 	}
+	return reps[0].Events, nil
+}
 
+// Sets the events of each rep. Reps from GetReps don't have their
+// events, since most commands don't need them and there can be a
+// lot of them, so commands that need them load them with this.
+func LoadEvents(db *sql.DB, reps []Rep) error {
+	if len(reps) == 0 {
+		return nil
+	}
+	ids := []string{}
+	repIndex := map[int]int{}
+	for i, rep := range reps {
+		ids = append(ids, strconv.Itoa(rep.Id))
+		repIndex[rep.Id] = i
+		reps[i].Events = nil
+	}
+
+	query := fmt.Sprintf("select %s, %s, %s, %s, %s from %s where %s in (%s) order by %s, %s;",
+		constants.REP_ID, constants.TS, constants.INDEX, constants.TYPED, constants.EXPECTED,
+		constants.EVENTS, constants.REP_ID, strings.Join(ids, ", "),
+		constants.REP_ID, constants.SEQUENCE)
+	rows, err := db.Query(query)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			repId int
+			ts    int64
+			e     event.Event
+		)
+		if err := rows.Scan(&repId, &ts, &e.I, &e.Typed, &e.Expected); err != nil {
+			return err
+		}
+		e.Ts = time.UnixMilli(ts)
+		i := repIndex[repId]
+		reps[i].Events = append(reps[i].Events, e)
+	}
+	return rows.Err()
+}
+
+// Checks if the database already has a rep of the exercise with
+// the given hash that started and ended at the same times.
+// The times are compared in milliseconds, the same as they're saved.
+func HasRep(db *sql.DB, hash string, start time.Time, end time.Time) (bool, error) {
+	query := fmt.Sprintf("select count(*) from reps where %s = ? and %s = ? and %s = ?;",
+		constants.HASH, constants.START, constants.END)
+	var count int
+	err := db.QueryRow(query, hash, start.UnixMilli(), end.UnixMilli()).Scan(&count)
+	return count > 0, err
+}
+
+// The saved text of an exercise.
+type Exercise struct {
+	Hash string
+	Name string
+	Text string
+}
+
+// Gets every saved exercise.
+func GetExercises(db *sql.DB) ([]Exercise, error) {
+	query := fmt.Sprintf("select %s, %s, %s from exercises order by %s;",
+		constants.HASH, constants.NAME, constants.TEXT, constants.NAME)
+	rows, err := db.Query(query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var exercises []Exercise
+	for rows.Next() {
+		var ex Exercise
+		if err := rows.Scan(&ex.Hash, &ex.Name, &ex.Text); err != nil {
+			return exercises, err
+		}
+		exercises = append(exercises, ex)
+	}
+	return exercises, rows.Err()
+}
+
+// Saves the text of an exercise, so its reps can be replayed later.
+// The text is keyed by its hash, so if it has been saved already,
+// then nothing happens.
+func InsertExercise(db *sql.DB, hash string, name string, text string) error {
+	query := fmt.Sprintf("insert or ignore into exercises (%s, %s, %s) values (?, ?, ?);",
+		constants.HASH, constants.NAME, constants.TEXT)
+	_, err := db.Exec(query, hash, name, text)
+	return err
+}
+
+// Gets the text of the exercise with the given hash.
+// If the text hasn't been saved, then an empty string is returned.
+func GetExerciseText(db *sql.DB, hash string) (string, error) {
+	query := fmt.Sprintf("select %s from exercises where %s = ?;", constants.TEXT, constants.HASH)
+	var text string
+	err := db.QueryRow(query, hash).Scan(&text)
+	if err == sql.ErrNoRows {
+		return "", nil
+	}
+	return text, err
+}
+
+// Gets the rep with the given id, along with its events.
+// If there's no rep with the id, then the returned rep is nil.
+func GetRep(db *sql.DB, id int) (*Rep, error) {
+	query := fmt.Sprintf("select * from reps where %s = ?;", constants.ID)
+	reps, err := queryReps(db, query, id)
+	if err != nil {
+		return nil, err
+	}
+	if len(reps) == 0 {
+		return nil, nil
+	}
+	if err := LoadEvents(db, reps); err != nil {
+		return nil, err
+	}
+	return &reps[0], nil
+}
+
+// Gets the fastest rep of the exercise with the given hash,
+// along with its events. Timed reps are skipped, since they
+// don't finish when the exercise's text is complete. If there
+// are no matching reps, then the returned rep is nil.
+func GetFastestRep(db *sql.DB, hash string) (*Rep, error) {
+	reps, err := GetReps(db, RepFilter{
+		Hashes:  []string{hash},
+		Max:     map[string]float64{constants.TIME_LIMIT: 0},
+		OrderBy: constants.DURATION,
+		Limit:   1,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(reps) == 0 {
+		return nil, nil
+	}
+	if err := LoadEvents(db, reps); err != nil {
+		return nil, err
+	}
+	return &reps[0], nil
+}
+
+// Gets the reps that match the filter. Their events
+// aren't loaded, see `LoadEvents`.
+func GetReps(db *sql.DB, filter RepFilter) ([]Rep, error) {
+	query, args, err := filter.Query()
+	if err != nil {
+		return nil, err
+	}
+	return queryReps(db, query, args...)
+}
+
+// Gets the reps from a query that selects every column of the reps table.
+func queryReps(db *sql.DB, query string, args ...any) ([]Rep, error) {
 	var reps []Rep
 
 	// Query to retrieve data
-	rows, err := db.Query(query)
+	rows, err := db.Query(query, args...)
 	if err != nil {
 		return reps, err
 	}
@@ -254,7 +447,9 @@ func GetReps(db *sql.DB, query string) ([]Rep, error) {
 			acc    float64
 			miss   int
 			errs   int
-			events string
+			lim    int64
+			strict bool
+			pauses string
 		)
 
 		// this should match the columns from the query input
@@ -271,7 +466,9 @@ func GetReps(db *sql.DB, query string) ([]Rep, error) {
 			&acc,
 			&miss,
 			&errs,
-			&events,
+			&lim,
+			&strict,
+			&pauses,
 		}
 
 		// The scan is dependent on the query that is performed
@@ -296,7 +493,9 @@ func GetReps(db *sql.DB, query string) ([]Rep, error) {
 			Acc:    acc,
 			Miss:   miss,
 			Errs:   errs,
-			Events: event.ParseEvents(events),
+			Lim:    time.Duration(lim),
+			Strict: strict,
+			Pauses: event.ParsePauses(pauses),
 		}
 
 		reps = append(reps, r)
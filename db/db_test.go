@@ -1,6 +1,7 @@
 package db
 
 import (
+	"database/sql"
 	"os"
 	"path"
 	"testing"
@@ -74,7 +75,7 @@ func TestGetStatsDb(t *testing.T) {
 		// Expected columns
 		expectedColumns := []string{
 			"id", "hash", "start", "end", "name", "lang",
-			"wpm", "raw", "dur", "acc", "miss", "errs", "events",
+			"wpm", "raw", "dur", "acc", "miss", "errs", "lim", "strict", "pauses",
 		}
 
 		// Collect actual column names
@@ -110,6 +111,54 @@ func TestGetStatsDb(t *testing.T) {
 		}
 	})
 
+	t.Run("add time limit column to an existing database", func(t *testing.T) {
+		tempDir := t.TempDir()
+		t.Setenv("SWEET_DB_LOCATION", tempDir)
+
+		oldDb, err := sql.Open("sqlite", path.Join(tempDir, "sweet.db"))
+		if err != nil {
+			t.Fatalf("failed to open old database: %v", err)
+		}
+		_, err = oldDb.Exec(`CREATE TABLE reps(
+  id integer primary key autoincrement not null,
+  hash string NOT NULL,
+  start integer not null,
+  end integer not null,
+  name text not null,
+  lang text,
+  wpm real not null check(wpm >= 0.0),
+  raw real not null check(raw >= 0.0),
+  dur integer not null check(dur >= 0),
+  acc real not null check(acc >= 0.0),
+  miss integer not null check(miss >= 0),
+  errs integer not null check(errs >= 0),
+  events text not null
+);
+insert into reps (hash, start, end, name, lang, wpm, raw, dur, acc, miss, errs, events)
+values ('abc', 0, 1000, 'old.go', 'go', 60, 60, 1000000000, 100, 0, 0, '');`)
+		oldDb.Close()
+		if err != nil {
+			t.Fatalf("failed to create old database: %v", err)
+		}
+
+		db, err := SweetDb()
+		if err != nil {
+			t.Fatalf("Should open an existing database: %v", err)
+		}
+		defer db.Close()
+
+		reps, err := GetReps(db, RepFilter{})
+		if err != nil {
+			t.Fatalf("Should get reps from an existing database: %v", err)
+		}
+		if len(reps) != 1 || reps[0].Name != "old.go" || reps[0].Lim != 0 {
+			t.Errorf("Existing rep should be untimed: %v", reps)
+		}
+		if len(reps) == 1 && len(reps[0].Pauses) != 0 {
+			t.Errorf("Existing rep shouldn't have pauses: %v", reps[0].Pauses)
+		}
+	})
+
 	t.Run("should error if the location doesn't exist", func(t *testing.T) {
 		os.Setenv("SWEET_DB_LOCATION", "/absolutely/impossible/path/that/cannot/exist")
 		defer os.Unsetenv("SWEET_DB_LOCATION")
@@ -121,20 +170,81 @@ func TestGetStatsDb(t *testing.T) {
 	})
 }
 
-func TestEventsToColumn(t *testing.T) {
-	want := "2024-10-07 16:29:26.916\t0\tc\tc\n" +
-		"2024-10-07 16:29:27.004\t1\to\to\n" +
-		"2024-10-07 16:29:27.095\t2\tn\tn\n" +
-		"2024-10-07 16:29:27.279\t3\ts\ts\n" +
-		"2024-10-07 16:29:27.416\t4\to\to\n" +
-		"2024-10-07 16:29:27.667\t5\tl\tl\n" +
-		"2024-10-07 16:29:27.784\t6\te\te\n" +
-		"2024-10-07 16:29:31.538\t7\tenter\tenter"
-	events := event.ParseEvents(want)
-	got := eventsStringToColumn(events)
-
-	if got != want {
-		t.Error("got != want")
+func TestEvents(t *testing.T) {
+	t.Setenv("SWEET_DB_LOCATION", t.TempDir())
+
+	db, err := SweetDb()
+	if err != nil {
+		t.Fatalf("failed to initialize sweet db: %v", err)
+	}
+	defer db.Close()
+
+	events := event.ParseEvents("2024-10-07 16:29:26.916\t0\tc\tc\n" +
+		"2024-10-07 16:29:27.004\t1\tp\to\n" +
+		"2024-10-07 16:29:27.095\t1\tbackspace\n" +
+		"2024-10-07 16:29:31.538\t1\to\to")
+	start := events[0].Ts
+	newRep := func(name string, events event.Events) Rep {
+		return Rep{Hash: name, Start: start, End: start.Add(5 * time.Second), Name: name, Events: events}
+	}
+
+	id, err := InsertRep(db, newRep("one.txt", events))
+	if err != nil {
+		t.Fatalf("failed to insert rep: %v", err)
+	}
+	otherId, err := InsertRep(db, newRep("two.txt", events[:1]))
+	if err != nil {
+		t.Fatalf("failed to insert rep: %v", err)
+	}
+	emptyId, err := InsertRep(db, newRep("three.txt", nil))
+	if err != nil {
+		t.Fatalf("failed to insert rep: %v", err)
+	}
+
+	got, err := GetEvents(db, int(id))
+	if err != nil {
+		t.Fatalf("failed to get events: %v", err)
+	}
+	if len(got) != len(events) {
+		t.Fatalf("got %d events, want %d", len(got), len(events))
+	}
+	for i := range events {
+		if !got[i].Matches(events[i]) {
+			t.Errorf("event %d: got %s, want %s", i, got[i], events[i])
+		}
+	}
+
+	reps, err := GetReps(db, RepFilter{})
+	if err != nil {
+		t.Fatalf("failed to get reps: %v", err)
+	}
+	for _, rep := range reps {
+		if rep.Events != nil {
+			t.Errorf("GetReps shouldn't load events, got %v", rep.Events)
+		}
+	}
+	if err := LoadEvents(db, reps); err != nil {
+		t.Fatalf("failed to load events: %v", err)
+	}
+	wantCounts := map[int]int{int(id): 4, int(otherId): 1, int(emptyId): 0}
+	for _, rep := range reps {
+		if len(rep.Events) != wantCounts[rep.Id] {
+			t.Errorf("rep %d: got %d events, want %d", rep.Id, len(rep.Events), wantCounts[rep.Id])
+		}
+	}
+
+	// A rep that fails to insert shouldn't leave its events behind.
+	invalid := newRep("invalid.txt", events)
+	invalid.Wpm = -1
+	if _, err := InsertRep(db, invalid); err == nil {
+		t.Fatalf("wanted error inserting an invalid rep, got nil")
+	}
+	var count int
+	if err := db.QueryRow("select count(*) from events;").Scan(&count); err != nil {
+		t.Fatalf("failed to count events: %v", err)
+	}
+	if count != 5 {
+		t.Errorf("got %d events in the table, want 5", count)
 	}
 }
 
@@ -225,3 +335,182 @@ func TestInsertRep(t *testing.T) {
 	})
 
 }
+
+func TestGetFastestRep(t *testing.T) {
+	tempDir := t.TempDir()
+	t.Setenv("SWEET_DB_LOCATION", tempDir)
+
+	db, err := SweetDb()
+	if err != nil {
+		t.Fatalf("failed to initialize sweet db: %v", err)
+	}
+	defer db.Close()
+
+	start := time.Date(2024, 10, 7, 13, 46, 47, 0, time.UTC)
+	newRep := func(hash string, dur time.Duration, lim time.Duration) Rep {
+		return Rep{
+			Hash:  hash,
+			Start: start,
+			End:   start.Add(dur),
+			Name:  "exercise.go",
+			Lang:  "go",
+			Dur:   dur,
+			Lim:   lim,
+		}
+	}
+	reps := []Rep{
+		newRep("abc", 20*time.Second, 0),
+		newRep("abc", 10*time.Second, 0),
+		newRep("abc", 5*time.Second, time.Minute), // timed, skipped
+		newRep("def", time.Second, 0),             // different exercise
+	}
+	for _, rep := range reps {
+		if _, err := InsertRep(db, rep); err != nil {
+			t.Fatalf("failed to insert rep: %v", err)
+		}
+	}
+
+	got, err := GetFastestRep(db, "abc")
+	if err != nil {
+		t.Fatalf("failed to get fastest rep: %v", err)
+	}
+	if got == nil || got.Dur != 10*time.Second {
+		t.Errorf("got %v, want the 10s rep", got)
+	}
+
+	got, err = GetFastestRep(db, "xyz")
+	if err != nil {
+		t.Fatalf("failed to get fastest rep: %v", err)
+	}
+	if got != nil {
+		t.Errorf("got %v, want nil", got)
+	}
+}
+
+func TestExerciseText(t *testing.T) {
+	tempDir := t.TempDir()
+	t.Setenv("SWEET_DB_LOCATION", tempDir)
+
+	db, err := SweetDb()
+	if err != nil {
+		t.Fatalf("failed to initialize sweet db: %v", err)
+	}
+	defer db.Close()
+
+	if err := InsertExercise(db, "abc", "hey.txt", "hey\n"); err != nil {
+		t.Fatalf("failed to insert exercise: %v", err)
+	}
+	// inserting the same text again should be ignored
+	if err := InsertExercise(db, "abc", "renamed.txt", "hey\n"); err != nil {
+		t.Fatalf("failed to insert exercise again: %v", err)
+	}
+
+	got, err := GetExerciseText(db, "abc")
+	if err != nil {
+		t.Fatalf("failed to get exercise text: %v", err)
+	}
+	if got != "hey\n" {
+		t.Errorf("got %q, want %q", got, "hey\n")
+	}
+
+	got, err = GetExerciseText(db, "def")
+	if err != nil {
+		t.Fatalf("failed to get missing exercise text: %v", err)
+	}
+	if got != "" {
+		t.Errorf("got %q, want empty string", got)
+	}
+
+	exercises, err := GetExercises(db)
+	if err != nil {
+		t.Fatalf("failed to get exercises: %v", err)
+	}
+	if len(exercises) != 1 || exercises[0] != (Exercise{Hash: "abc", Name: "hey.txt", Text: "hey\n"}) {
+		t.Errorf("got %v, want the hey.txt exercise", exercises)
+	}
+}
+
+func TestGetRep(t *testing.T) {
+	tempDir := t.TempDir()
+	t.Setenv("SWEET_DB_LOCATION", tempDir)
+
+	db, err := SweetDb()
+	if err != nil {
+		t.Fatalf("failed to initialize sweet db: %v", err)
+	}
+	defer db.Close()
+
+	start := time.Date(2024, 10, 7, 13, 46, 47, 0, time.UTC)
+	pause := event.Pause{Start: start.Add(100 * time.Millisecond), End: start.Add(time.Second)}
+	id, err := InsertRep(db, Rep{
+		Hash:   "abc",
+		Start:  start,
+		End:    start.Add(2 * time.Second),
+		Name:   "hey.txt",
+		Lang:   "txt",
+		Dur:    time.Second,
+		Pauses: event.Pauses{pause},
+	})
+	if err != nil {
+		t.Fatalf("failed to insert rep: %v", err)
+	}
+
+	got, err := GetRep(db, int(id))
+	if err != nil {
+		t.Fatalf("failed to get rep: %v", err)
+	}
+	if got == nil || got.Name != "hey.txt" {
+		t.Fatalf("got %v, want rep hey.txt", got)
+	}
+	if len(got.Pauses) != 1 || !got.Pauses[0].Start.Equal(pause.Start) || !got.Pauses[0].End.Equal(pause.End) {
+		t.Errorf("got pauses %v, want %v", got.Pauses, pause)
+	}
+
+	got, err = GetRep(db, int(id)+1)
+	if err != nil {
+		t.Fatalf("failed to get missing rep: %v", err)
+	}
+	if got != nil {
+		t.Errorf("got %v, want nil", got)
+	}
+}
+
+func TestHasRep(t *testing.T) {
+	tempDir := t.TempDir()
+	t.Setenv("SWEET_DB_LOCATION", tempDir)
+
+	db, err := SweetDb()
+	if err != nil {
+		t.Fatalf("failed to initialize sweet db: %v", err)
+	}
+	defer db.Close()
+
+	start := time.Date(2024, 10, 7, 13, 46, 47, 0, time.UTC)
+	end := start.Add(time.Second)
+	if _, err := InsertRep(db, Rep{Hash: "abc", Start: start, End: end, Name: "hey.txt"}); err != nil {
+		t.Fatalf("failed to insert rep: %v", err)
+	}
+
+	testCases := []struct {
+		name  string
+		hash  string
+		start time.Time
+		end   time.Time
+		want  bool
+	}{
+		{"same rep", "abc", start, end, true},
+		{"same rep, in a different time zone", "abc", start.Local(), end.Local(), true},
+		{"different exercise", "def", start, end, false},
+		{"different start", "abc", start.Add(time.Millisecond), end, false},
+		{"different end", "abc", start, end.Add(time.Millisecond), false},
+	}
+	for _, tc := range testCases {
+		got, err := HasRep(db, tc.hash, tc.start, tc.end)
+		if err != nil {
+			t.Fatalf("%s: failed to check rep: %v", tc.name, err)
+		}
+		if got != tc.want {
+			t.Errorf("%s: got %t, want %t", tc.name, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,157 @@
+package db
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/NicksPatties/sweet/constants"
+)
+
+// Chooses which reps to get from the database, and in which order.
+// Every field is optional, so the zero value gets every rep,
+// ordered by when they started.
+type RepFilter struct {
+	// Name of the exercise. A `*` matches any number of characters.
+	Name string
+
+	// Languages of the exercises, any of which match.
+	Langs []string
+
+	// Hashes of the exercises, any of which match.
+	Hashes []string
+
+	// Beginning of the hash of the exercise, so the short
+	// hashes that stats shows match their full hashes.
+	HashPrefix string
+
+	// Reps that started at or after Start, and ended at or
+	// before End. Zero times aren't used.
+	Start time.Time
+	End   time.Time
+
+	// Minimum and maximum values of metric columns, like
+	// `wpm` or `acc`. Durations are in nanoseconds.
+	Min map[string]float64
+	Max map[string]float64
+
+	// Column to order the reps by, `start` if empty.
+	OrderBy string
+
+	// Orders the reps from the largest value to the smallest.
+	Desc bool
+
+	// Maximum number of reps to get, or 0 to get all of them.
+	Limit int
+}
+
+// Columns of the reps table that a filter can order by.
+var orderColumns = []string{
+	constants.ID, constants.HASH, constants.START, constants.END,
+	constants.NAME, constants.LANGUAGE, constants.WPM, constants.RAW_WPM,
+	constants.DURATION, constants.ACCURACY, constants.MISTAKES,
+	constants.UNCORRECTED_ERRORS, constants.TIME_LIMIT,
+}
+
+// Columns of the reps table that can have minimum and maximum values.
+var metricColumns = []string{
+	constants.WPM, constants.RAW_WPM, constants.DURATION, constants.ACCURACY,
+	constants.MISTAKES, constants.UNCORRECTED_ERRORS, constants.TIME_LIMIT,
+}
+
+// Converts a name with `*` wildcards to a like pattern. Like's own
+// wildcards are escaped, so they match themselves.
+func likePattern(name string) string {
+	r := strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`, `*`, `%`)
+	return r.Replace(name)
+}
+
+// Returns a placeholder for each value, like `?, ?, ?`,
+// and adds the values to args.
+func placeholders[T any](values []T, args *[]any) string {
+	marks := make([]string, len(values))
+	for i, v := range values {
+		marks[i] = "?"
+		*args = append(*args, v)
+	}
+	return strings.Join(marks, ", ")
+}
+
+// Compiles the filter into a query of the reps table and its
+// arguments. Values are passed as arguments instead of being
+// written into the query, so they never need to be escaped.
+func (f RepFilter) Query() (string, []any, error) {
+	conds := []string{}
+	args := []any{}
+
+	if f.Name != "" {
+		conds = append(conds, fmt.Sprintf(`%s like ? escape '\'`, constants.NAME))
+		args = append(args, likePattern(f.Name))
+	}
+	if len(f.Langs) > 0 {
+		conds = append(conds, fmt.Sprintf("%s in (%s)", constants.LANGUAGE, placeholders(f.Langs, &args)))
+	}
+	if len(f.Hashes) > 0 {
+		conds = append(conds, fmt.Sprintf("%s in (%s)", constants.HASH, placeholders(f.Hashes, &args)))
+	}
+	if f.HashPrefix != "" {
+		conds = append(conds, fmt.Sprintf(`%s like ? escape '\'`, constants.HASH))
+		args = append(args, likePattern(f.HashPrefix)+"%")
+	}
+	if !f.Start.IsZero() {
+		conds = append(conds, fmt.Sprintf("%s >= ?", constants.START))
+		args = append(args, f.Start.UnixMilli())
+	}
+	if !f.End.IsZero() {
+		conds = append(conds, fmt.Sprintf("%s <= ?", constants.END))
+		args = append(args, f.End.UnixMilli())
+	}
+
+	thresholds := []struct {
+		values map[string]float64
+		op     string
+	}{
+		{f.Min, ">="},
+		{f.Max, "<="},
+	}
+	for _, t := range thresholds {
+		// sorted, so the same filter always makes the same query
+		cols := []string{}
+		for col := range t.values {
+			cols = append(cols, col)
+		}
+		slices.Sort(cols)
+		for _, col := range cols {
+			if !slices.Contains(metricColumns, col) {
+				return "", nil, fmt.Errorf("can't filter by %s (use one of %s)", col, strings.Join(metricColumns, ", "))
+			}
+			conds = append(conds, fmt.Sprintf("%s %s ?", col, t.op))
+			args = append(args, t.values[col])
+		}
+	}
+
+	query := "select * from reps"
+	if len(conds) > 0 {
+		query += " where " + strings.Join(conds, " and ")
+	}
+
+	orderBy := f.OrderBy
+	if orderBy == "" {
+		orderBy = constants.START
+	}
+	if !slices.Contains(orderColumns, orderBy) {
+		return "", nil, fmt.Errorf("can't order by %s (use one of %s)", orderBy, strings.Join(orderColumns, ", "))
+	}
+	query += " order by " + orderBy
+	if f.Desc {
+		query += " desc"
+	}
+
+	if f.Limit > 0 {
+		query += " limit ?"
+		args = append(args, f.Limit)
+	}
+
+	return query + ";", args, nil
+}
@@ -0,0 +1,140 @@
+package db
+
+import (
+	"fmt"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestRepFilterQuery(t *testing.T) {
+	start := time.Date(2024, 10, 7, 0, 0, 0, 0, time.UTC)
+	end := start.AddDate(0, 0, 1)
+
+	testCases := []struct {
+		name      string
+		in        RepFilter
+		wantQuery string
+		wantArgs  []any
+		wantErr   bool
+	}{
+		{
+			name:      "every rep",
+			in:        RepFilter{},
+			wantQuery: "select * from reps order by start;",
+		},
+		{
+			name:      "name with wildcards",
+			in:        RepFilter{Name: "it's_a*"},
+			wantQuery: `select * from reps where name like ? escape '\' order by start;`,
+			wantArgs:  []any{`it's\_a%`},
+		},
+		{
+			name: "combined filters",
+			in: RepFilter{
+				Langs:      []string{"go", "py"},
+				Hashes:     []string{"abc"},
+				HashPrefix: "ab",
+				Start:      start,
+				End:        end,
+			},
+			wantQuery: `select * from reps where lang in (?, ?) and hash in (?) and hash like ? escape '\' and start >= ? and end <= ? order by start;`,
+			wantArgs:  []any{"go", "py", "abc", "ab%", start.UnixMilli(), end.UnixMilli()},
+		},
+		{
+			name: "thresholds, order, and limit",
+			in: RepFilter{
+				Min:     map[string]float64{"wpm": 60, "acc": 90},
+				Max:     map[string]float64{"lim": 0},
+				OrderBy: "wpm",
+				Desc:    true,
+				Limit:   5,
+			},
+			wantQuery: "select * from reps where acc >= ? and wpm >= ? and lim <= ? order by wpm desc limit ?;",
+			wantArgs:  []any{90.0, 60.0, 0.0, 5},
+		},
+		{
+			name:    "unknown threshold column",
+			in:      RepFilter{Min: map[string]float64{"name": 1}},
+			wantErr: true,
+		},
+		{
+			name:    "unknown order column",
+			in:      RepFilter{OrderBy: "start; drop table reps"},
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		gotQuery, gotArgs, err := tc.in.Query()
+		if tc.wantErr {
+			if err == nil {
+				t.Errorf("%s: wanted error, got nil", tc.name)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("%s: wanted no error, got %v", tc.name, err)
+		}
+		if gotQuery != tc.wantQuery {
+			t.Errorf("%s:\n  got:  %s\n  want: %s", tc.name, gotQuery, tc.wantQuery)
+		}
+		if fmt.Sprint(gotArgs) != fmt.Sprint(tc.wantArgs) {
+			t.Errorf("%s: got args %v, want %v", tc.name, gotArgs, tc.wantArgs)
+		}
+	}
+}
+
+func TestGetReps_filter(t *testing.T) {
+	t.Setenv("SWEET_DB_LOCATION", t.TempDir())
+
+	db, err := SweetDb()
+	if err != nil {
+		t.Fatalf("failed to initialize sweet db: %v", err)
+	}
+	defer db.Close()
+
+	start := time.Date(2024, 10, 7, 13, 0, 0, 0, time.UTC)
+	reps := []Rep{
+		{Hash: "a", Name: "it's.go", Lang: "go", Wpm: 50},
+		{Hash: "b", Name: "hello.go", Lang: "go", Wpm: 70},
+		{Hash: "c", Name: "hello.py", Lang: "py", Wpm: 80},
+		{Hash: "d", Name: "hello_world.txt", Lang: "txt", Wpm: 90},
+	}
+	for i, rep := range reps {
+		rep.Start = start.Add(time.Duration(i) * time.Minute)
+		rep.End = rep.Start.Add(time.Second)
+		if _, err := InsertRep(db, rep); err != nil {
+			t.Fatalf("failed to insert rep: %v", err)
+		}
+	}
+
+	testCases := []struct {
+		name string
+		in   RepFilter
+		want []string
+	}{
+		{"every rep", RepFilter{}, []string{"a", "b", "c", "d"}},
+		{"name with a quote", RepFilter{Name: "it's.go"}, []string{"a"}},
+		{"name wildcard", RepFilter{Name: "hello*"}, []string{"b", "c", "d"}},
+		{"underscore isn't a wildcard", RepFilter{Name: "hello_*"}, []string{"d"}},
+		{"name and language", RepFilter{Name: "hello*", Langs: []string{"go", "py"}}, []string{"b", "c"}},
+		{"hash prefix", RepFilter{HashPrefix: "c"}, []string{"c"}},
+		{"time range", RepFilter{Start: start.Add(time.Minute), End: start.Add(2*time.Minute + time.Second)}, []string{"b", "c"}},
+		{"fastest two", RepFilter{Min: map[string]float64{"wpm": 60}, OrderBy: "wpm", Desc: true, Limit: 2}, []string{"d", "c"}},
+	}
+
+	for _, tc := range testCases {
+		got, err := GetReps(db, tc.in)
+		if err != nil {
+			t.Fatalf("%s: failed to get reps: %v", tc.name, err)
+		}
+		hashes := []string{}
+		for _, rep := range got {
+			hashes = append(hashes, rep.Hash)
+		}
+		if !slices.Equal(hashes, tc.want) {
+			t.Errorf("%s: got %v, want %v", tc.name, hashes, tc.want)
+		}
+	}
+}
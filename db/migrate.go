@@ -0,0 +1,247 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/NicksPatties/sweet/constants"
+	"github.com/NicksPatties/sweet/event"
+)
+
+// A change to the database's tables.
+//
+// Migrations run in order, and each one runs once, when a database
+// that's older than it is opened. To change the tables, append a new
+// migration to the end of `migrations`. Never change or remove a
+// migration that has been released, since databases that ran it
+// won't run it again.
+type migration struct {
+	description string
+	up          func(tx *sql.Tx) error
+}
+
+// The schema version of a database is the number of migrations that
+// have run on it.
+//
+// Databases made before the schema_version table existed start at
+// version 0, even though they may have some of these tables and
+// columns already. That's why these first migrations check if their
+// changes have been made before making them.
+var migrations = []migration{
+	{
+		description: "create the reps table",
+		up: func(tx *sql.Tx) error {
+			_, err := tx.Exec(fmt.Sprintf(`
+CREATE TABLE if not exists reps(
+  %s integer primary key autoincrement not null,
+  -- md5 hash of the exercise file's contents
+  %s string NOT NULL,
+  -- start time in unix milliseconds
+  %s integer not null,
+  -- end time in unix milliseconds
+  %s integer not null,
+  -- name of the exercise file, includes extension if present
+  %s text not null,
+  -- language: extension of the exercise file, or "" if there is none.
+  %s text,
+  -- words per minute
+  %s real not null check(wpm >= 0.0),
+  -- raw words per minute
+  %s real not null check(raw >= 0.0),
+  -- duration: duration of rep in **nanoseconds**
+  %s integer not null check(dur >= 0),
+  -- accuracy: float between [0, 100]
+  %s real not null check(acc >= 0.0),
+  -- mistakes: must be gte 0
+  %s integer not null check(miss >= 0),
+  -- uncorrected errors: must be gte 0
+  %s integer not null check(errs >= 0),
+  -- array of events, events are separated by '\n'
+  %s text not null
+);`,
+				constants.ID, constants.HASH, constants.START, constants.END, constants.NAME, constants.LANGUAGE, constants.WPM, constants.RAW_WPM,
+				constants.DURATION, constants.ACCURACY, constants.MISTAKES, constants.UNCORRECTED_ERRORS, constants.EVENTS,
+			))
+			return err
+		},
+	},
+	{
+		description: "add the time limit of timed reps",
+		up: func(tx *sql.Tx) error {
+			// time limit of a timed rep in **nanoseconds**, or 0 if untimed
+			return addColumnIfMissing(tx, "reps", constants.TIME_LIMIT,
+				"integer not null default 0 check(lim >= 0)")
+		},
+	},
+	{
+		description: "create the exercises table",
+		up: func(tx *sql.Tx) error {
+			_, err := tx.Exec(fmt.Sprintf(`
+CREATE TABLE if not exists exercises(
+  -- md5 hash of the exercise's text
+  %s text primary key not null,
+  -- name of the exercise file when its text was first saved
+  %s text not null,
+  -- the text of the exercise
+  %s text not null
+);`,
+				constants.HASH, constants.NAME, constants.TEXT,
+			))
+			return err
+		},
+	},
+	{
+		description: "add strict reps",
+		up: func(tx *sql.Tx) error {
+			// 1 if mistakes had to be corrected before moving on, 0 otherwise
+			return addColumnIfMissing(tx, "reps", constants.STRICT,
+				"integer not null default 0 check(strict in (0, 1))")
+		},
+	},
+	{
+		description: "add the pauses of reps",
+		up: func(tx *sql.Tx) error {
+			// array of pauses, pauses are separated by '\n'
+			return addColumnIfMissing(tx, "reps", constants.PAUSES, "text not null default ''")
+		},
+	},
+	{
+		description: "move events to their own table",
+		up: func(tx *sql.Tx) error {
+			_, err := tx.Exec(fmt.Sprintf(`
+CREATE TABLE %s(
+  -- id of the rep the event belongs to
+  %s integer not null references reps(id) on delete cascade,
+  -- order of the event in its rep, starting at 0
+  %s integer not null check(seq >= 0),
+  -- time of the event in unix milliseconds
+  %s integer not null,
+  -- index of the exercise's text when the key was typed
+  %s integer not null,
+  -- the key that was typed
+  %s text not null,
+  -- the character that was expected, or "" if there is none
+  %s text not null default '',
+  primary key (%s, %s)
+);
+CREATE INDEX events_typed on %s(%s);
+CREATE INDEX events_expected on %s(%s);`,
+				constants.EVENTS, constants.REP_ID, constants.SEQUENCE, constants.TS, constants.INDEX, constants.TYPED, constants.EXPECTED,
+				constants.REP_ID, constants.SEQUENCE,
+				constants.EVENTS, constants.TYPED,
+				constants.EVENTS, constants.EXPECTED,
+			))
+			if err != nil {
+				return err
+			}
+
+			// Read every rep's events before inserting any, so the
+			// query isn't open while the events table is written to.
+			rows, err := tx.Query(fmt.Sprintf("select %s, %s from reps;", constants.ID, constants.EVENTS))
+			if err != nil {
+				return err
+			}
+			blobs := map[int]string{}
+			for rows.Next() {
+				var (
+					id   int
+					blob string
+				)
+				if err := rows.Scan(&id, &blob); err != nil {
+					rows.Close()
+					return err
+				}
+				blobs[id] = blob
+			}
+			rows.Close()
+			if err := rows.Err(); err != nil {
+				return err
+			}
+
+			for id, blob := range blobs {
+				if err := insertEvents(tx, id, event.ParseEvents(blob)); err != nil {
+					return err
+				}
+			}
+
+			_, err = tx.Exec(fmt.Sprintf("alter table reps drop column %s;", constants.EVENTS))
+			return err
+		},
+	},
+}
+
+// The schema version of a database with every migration.
+func latestVersion() int {
+	return len(migrations)
+}
+
+// Gets the schema version of the database. Databases without
+// a schema_version table are at version 0.
+func schemaVersion(db *sql.DB) (int, error) {
+	_, err := db.Exec("CREATE TABLE if not exists schema_version(version integer not null);")
+	if err != nil {
+		return 0, err
+	}
+	var version int
+	err = db.QueryRow("select version from schema_version;").Scan(&version)
+	if err == sql.ErrNoRows {
+		return 0, nil
+	}
+	return version, err
+}
+
+// Runs the migrations the database hasn't run yet. Each migration
+// runs in its own transaction along with the update of the schema
+// version, so a failed migration leaves the database at the version
+// before it.
+func migrate(db *sql.DB) error {
+	version, err := schemaVersion(db)
+	if err != nil {
+		return fmt.Errorf("failed to get schema version: %v", err)
+	}
+	if version > latestVersion() {
+		return fmt.Errorf("database schema version %d is newer than this version of sweet supports (%d), please update sweet",
+			version, latestVersion())
+	}
+
+	for i := version; i < len(migrations); i++ {
+		m := migrations[i]
+		tx, err := db.Begin()
+		if err != nil {
+			return err
+		}
+		if err := m.up(tx); err != nil {
+			tx.Rollback()
+			return fmt.Errorf("failed to %s: %v", m.description, err)
+		}
+		if err := setSchemaVersion(tx, i+1); err != nil {
+			tx.Rollback()
+			return fmt.Errorf("failed to set schema version: %v", err)
+		}
+		if err := tx.Commit(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func setSchemaVersion(tx *sql.Tx, version int) error {
+	if _, err := tx.Exec("delete from schema_version;"); err != nil {
+		return err
+	}
+	_, err := tx.Exec("insert into schema_version (version) values (?);", version)
+	return err
+}
+
+// Adds a column to a table if the table doesn't have it already.
+// The definition is everything that follows the column's name
+// in an `alter table ... add column` statement.
+func addColumnIfMissing(tx *sql.Tx, table string, column string, definition string) error {
+	columns, err := tableColumns(tx, table)
+	if err != nil || columns[column] {
+		return err
+	}
+
+	_, err = tx.Exec(fmt.Sprintf("alter table %s add column %s %s;", table, column, definition))
+	return err
+}
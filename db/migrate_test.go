@@ -0,0 +1,200 @@
+package db
+
+import (
+	"database/sql"
+	"os"
+	"path"
+	"testing"
+	"time"
+)
+
+// Copies a fixture database to a temporary directory, so
+// migrating it doesn't change the fixture.
+func copyFixture(t *testing.T, name string) string {
+	data, err := os.ReadFile(path.Join("testdata", name))
+	if err != nil {
+		t.Fatalf("failed to read fixture %s: %v", name, err)
+	}
+	file := path.Join(t.TempDir(), name)
+	if err := os.WriteFile(file, data, 0644); err != nil {
+		t.Fatalf("failed to copy fixture %s: %v", name, err)
+	}
+	return file
+}
+
+func getSchemaVersion(t *testing.T, db *sql.DB) int {
+	version, err := schemaVersion(db)
+	if err != nil {
+		t.Fatalf("failed to get schema version: %v", err)
+	}
+	return version
+}
+
+func TestMigrate_fixtures(t *testing.T) {
+	testCases := []struct {
+		fixture       string
+		wantReps      int
+		wantExercises int
+		check         func(t *testing.T, reps []Rep)
+	}{
+		{
+			fixture:  "baseline.db",
+			wantReps: 2,
+			check: func(t *testing.T, reps []Rep) {
+				for _, rep := range reps {
+					if rep.Lim != 0 || rep.Strict || len(rep.Pauses) != 0 {
+						t.Errorf("old rep should be untimed, not strict, and not paused: %v", rep)
+					}
+				}
+				if len(reps[0].Events) != 2 || reps[0].Events[1].Typed != "i" {
+					t.Fatalf("got events %v", reps[0].Events)
+				}
+				if reps[0].Events[1].Ts.Sub(reps[0].Events[0].Ts) != time.Second {
+					t.Errorf("got event times %s and %s, want them a second apart", reps[0].Events[0].Ts, reps[0].Events[1].Ts)
+				}
+			},
+		},
+		{
+			fixture:       "replay.db",
+			wantReps:      3,
+			wantExercises: 1,
+			check: func(t *testing.T, reps []Rep) {
+				if reps[2].Lim != time.Minute || reps[2].Strict {
+					t.Errorf("got timed rep %v", reps[2])
+				}
+			},
+		},
+		{
+			fixture:       "pauses.db",
+			wantReps:      3,
+			wantExercises: 1,
+			check: func(t *testing.T, reps []Rep) {
+				rep := reps[2]
+				if rep.Lim != time.Minute || !rep.Strict || len(rep.Pauses) != 1 {
+					t.Errorf("got rep %v", rep)
+				}
+				if len(rep.Pauses) == 1 && rep.Pauses[0].End.Sub(rep.Pauses[0].Start) != 10*time.Second {
+					t.Errorf("got pause %v", rep.Pauses[0])
+				}
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.fixture, func(t *testing.T) {
+			db, err := Open(copyFixture(t, tc.fixture))
+			if err != nil {
+				t.Fatalf("failed to open fixture: %v", err)
+			}
+			defer db.Close()
+
+			if got := getSchemaVersion(t, db); got != latestVersion() {
+				t.Errorf("got schema version %d, want %d", got, latestVersion())
+			}
+
+			reps, err := GetReps(db, RepFilter{})
+			if err != nil {
+				t.Fatalf("failed to get reps: %v", err)
+			}
+			if len(reps) != tc.wantReps {
+				t.Fatalf("got %d reps, want %d", len(reps), tc.wantReps)
+			}
+			if err := LoadEvents(db, reps); err != nil {
+				t.Fatalf("failed to load events: %v", err)
+			}
+			tc.check(t, reps)
+
+			exercises, err := GetExercises(db)
+			if err != nil {
+				t.Fatalf("failed to get exercises: %v", err)
+			}
+			if len(exercises) != tc.wantExercises {
+				t.Errorf("got %d exercises, want %d", len(exercises), tc.wantExercises)
+			}
+
+			// New reps should still be saved like before.
+			start := time.Date(2024, 10, 8, 0, 0, 0, 0, time.UTC)
+			_, err = InsertRep(db, Rep{Hash: "ghi", Start: start, End: start.Add(time.Second), Name: "new.txt", Lim: time.Second, Strict: true})
+			if err != nil {
+				t.Fatalf("failed to insert rep into migrated database: %v", err)
+			}
+		})
+	}
+}
+
+func TestMigrate(t *testing.T) {
+	file := path.Join(t.TempDir(), "sweet.db")
+
+	t.Run("new database is at the latest version", func(t *testing.T) {
+		db, err := Open(file)
+		if err != nil {
+			t.Fatalf("failed to open database: %v", err)
+		}
+		defer db.Close()
+		if got := getSchemaVersion(t, db); got != latestVersion() {
+			t.Errorf("got schema version %d, want %d", got, latestVersion())
+		}
+		var rows int
+		if err := db.QueryRow("select count(*) from schema_version;").Scan(&rows); err != nil || rows != 1 {
+			t.Errorf("schema_version should have one row, got %d (%v)", rows, err)
+		}
+	})
+
+	t.Run("opening again doesn't run migrations again", func(t *testing.T) {
+		db, err := Open(file)
+		if err != nil {
+			t.Fatalf("failed to open database again: %v", err)
+		}
+		defer db.Close()
+		if got := getSchemaVersion(t, db); got != latestVersion() {
+			t.Errorf("got schema version %d, want %d", got, latestVersion())
+		}
+	})
+
+	t.Run("newer database is an error", func(t *testing.T) {
+		db, err := Open(file)
+		if err != nil {
+			t.Fatalf("failed to open database: %v", err)
+		}
+		_, err = db.Exec("update schema_version set version = ?;", latestVersion()+1)
+		db.Close()
+		if err != nil {
+			t.Fatalf("failed to update schema version: %v", err)
+		}
+
+		if _, err := Open(file); err == nil {
+			t.Errorf("wanted error for a database from a newer version, got nil")
+		}
+	})
+
+	t.Run("failed migration is rolled back", func(t *testing.T) {
+		db, err := Open(path.Join(t.TempDir(), "sweet.db"))
+		if err != nil {
+			t.Fatalf("failed to open database: %v", err)
+		}
+		defer db.Close()
+
+		prev := migrations
+		defer func() { migrations = prev }()
+		migrations = append(migrations[:len(migrations):len(migrations)], migration{
+			description: "break things",
+			up: func(tx *sql.Tx) error {
+				if _, err := tx.Exec("create table broken(id integer);"); err != nil {
+					return err
+				}
+				_, err := tx.Exec("not even sql;")
+				return err
+			},
+		})
+
+		if err := migrate(db); err == nil {
+			t.Fatalf("wanted error from a broken migration, got nil")
+		}
+		if got := getSchemaVersion(t, db); got != len(prev) {
+			t.Errorf("got schema version %d, want %d", got, len(prev))
+		}
+		if _, err := db.Exec("select * from broken;"); err == nil {
+			t.Errorf("broken migration's table should have been rolled back")
+		}
+	})
+}
@@ -0,0 +1,135 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/NicksPatties/sweet/constants"
+	"github.com/NicksPatties/sweet/event"
+)
+
+// Opens the sweet database at the given file without changing it.
+// Unlike `Open`, the database isn't migrated, so it can be from
+// any version of sweet, and should be read with `ReadReps` and
+// `ReadExercises`, which work with the tables of any version.
+//
+// If an error is returned from this function, then the pointer
+// will be `nil`.
+func OpenReadOnly(file string) (*sql.DB, error) {
+	uri := "file:" + (&url.URL{Path: file}).EscapedPath() + "?mode=ro"
+	db, err := sql.Open("sqlite", uri)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open database: %v", err)
+	}
+
+	if err := db.Ping(); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to connect to database: %v", err)
+	}
+	return db, nil
+}
+
+// The columns of a table, or none if the table doesn't exist.
+func tableColumns(q interface {
+	Query(query string, args ...any) (*sql.Rows, error)
+}, table string) (map[string]bool, error) {
+	rows, err := q.Query(fmt.Sprintf("PRAGMA table_info(%s);", table))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	columns := map[string]bool{}
+	for rows.Next() {
+		var (
+			cid       int
+			name      string
+			typ       string
+			notNull   int
+			dfltValue any
+			pk        int
+		)
+		if err := rows.Scan(&cid, &name, &typ, &notNull, &dfltValue, &pk); err != nil {
+			return nil, err
+		}
+		columns[name] = true
+	}
+	return columns, rows.Err()
+}
+
+// Columns added to the reps table after it was created, and the
+// values of reps from before they were added.
+var addedRepColumns = []struct {
+	name  string
+	value string
+}{
+	{constants.TIME_LIMIT, "0"},
+	{constants.STRICT, "0"},
+	{constants.PAUSES, "''"},
+}
+
+// Gets every rep of a database, with their events, whether or not
+// the database has been migrated. Reps from before a column was
+// added get the value the migration that added it gives them.
+func ReadReps(db *sql.DB) ([]Rep, error) {
+	columns, err := tableColumns(db, "reps")
+	if err != nil || len(columns) == 0 {
+		return nil, err
+	}
+
+	selected := []string{
+		constants.ID, constants.HASH, constants.START, constants.END, constants.NAME, constants.LANGUAGE,
+		constants.WPM, constants.RAW_WPM, constants.DURATION, constants.ACCURACY, constants.MISTAKES,
+		constants.UNCORRECTED_ERRORS,
+	}
+	for _, c := range addedRepColumns {
+		if columns[c.name] {
+			selected = append(selected, c.name)
+		} else {
+			selected = append(selected, c.value)
+		}
+	}
+	query := fmt.Sprintf("select %s from reps order by %s;", strings.Join(selected, ", "), constants.ID)
+	reps, err := queryReps(db, query)
+	if err != nil {
+		return nil, err
+	}
+
+	// Before the events table, each rep's events were in a column.
+	if !columns[constants.EVENTS] {
+		return reps, LoadEvents(db, reps)
+	}
+	repIndex := map[int]int{}
+	for i, rep := range reps {
+		repIndex[rep.Id] = i
+	}
+	query = fmt.Sprintf("select %s, %s from reps;", constants.ID, constants.EVENTS)
+	rows, err := db.Query(query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var (
+			id   int
+			blob string
+		)
+		if err := rows.Scan(&id, &blob); err != nil {
+			return nil, err
+		}
+		reps[repIndex[id]].Events = event.ParseEvents(blob)
+	}
+	return reps, rows.Err()
+}
+
+// Gets every saved exercise of a database, whether or not
+// the database has been migrated.
+func ReadExercises(db *sql.DB) ([]Exercise, error) {
+	columns, err := tableColumns(db, "exercises")
+	if err != nil || len(columns) == 0 {
+		return nil, err
+	}
+	return GetExercises(db)
+}
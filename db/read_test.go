@@ -0,0 +1,97 @@
+package db
+
+import (
+	"bytes"
+	"os"
+	"path"
+	"reflect"
+	"testing"
+)
+
+func TestReadReps_fixtures(t *testing.T) {
+	for _, fixture := range []string{"baseline.db", "replay.db", "pauses.db"} {
+		t.Run(fixture, func(t *testing.T) {
+			file := copyFixture(t, fixture)
+			before, err := os.ReadFile(file)
+			if err != nil {
+				t.Fatalf("failed to read fixture: %v", err)
+			}
+
+			db, err := OpenReadOnly(file)
+			if err != nil {
+				t.Fatalf("failed to open fixture: %v", err)
+			}
+			got, err := ReadReps(db)
+			if err != nil {
+				t.Fatalf("failed to read reps: %v", err)
+			}
+			gotExercises, err := ReadExercises(db)
+			if err != nil {
+				t.Fatalf("failed to read exercises: %v", err)
+			}
+			db.Close()
+
+			after, err := os.ReadFile(file)
+			if err != nil {
+				t.Fatalf("failed to read fixture: %v", err)
+			}
+			if !bytes.Equal(before, after) {
+				t.Errorf("reading the fixture changed it")
+			}
+
+			// The reps should be the same as the migrated database's.
+			migrated, err := Open(file)
+			if err != nil {
+				t.Fatalf("failed to migrate fixture: %v", err)
+			}
+			defer migrated.Close()
+			want, err := GetReps(migrated, RepFilter{})
+			if err != nil {
+				t.Fatalf("failed to get reps: %v", err)
+			}
+			if err := LoadEvents(migrated, want); err != nil {
+				t.Fatalf("failed to load events: %v", err)
+			}
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("got reps\n%v\nwant\n%v", got, want)
+			}
+			wantExercises, err := GetExercises(migrated)
+			if err != nil {
+				t.Fatalf("failed to get exercises: %v", err)
+			}
+			if len(gotExercises) != len(wantExercises) {
+				t.Errorf("got %d exercises, want %d", len(gotExercises), len(wantExercises))
+			}
+		})
+	}
+}
+
+func TestOpenReadOnly(t *testing.T) {
+	file := path.Join(t.TempDir(), "sweet.db")
+
+	if _, err := OpenReadOnly(file); err == nil {
+		t.Errorf("wanted error for a missing database, got nil")
+	}
+	if _, err := os.Stat(file); err == nil {
+		t.Errorf("opening a missing database shouldn't create it")
+	}
+
+	db, err := Open(file)
+	if err != nil {
+		t.Fatalf("failed to open database: %v", err)
+	}
+	_, err = db.Exec("update schema_version set version = ?;", latestVersion()+1)
+	db.Close()
+	if err != nil {
+		t.Fatalf("failed to update schema version: %v", err)
+	}
+
+	db, err = OpenReadOnly(file)
+	if err != nil {
+		t.Fatalf("wanted a database from a newer version to open, got %v", err)
+	}
+	defer db.Close()
+	if _, err := db.Exec("delete from reps;"); err == nil {
+		t.Errorf("wanted error writing to a read only database, got nil")
+	}
+}
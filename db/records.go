@@ -0,0 +1,264 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/NicksPatties/sweet/constants"
+)
+
+// The best results of a group of reps.
+type Best struct {
+	// The value the reps are grouped by, like a language or
+	// an exercise's hash. Empty if the reps aren't grouped.
+	Group string
+
+	// Name of an exercise in the group.
+	Name string
+
+	// Number of reps in the group.
+	Reps int
+
+	// Highest wpm and accuracy of the reps.
+	Wpm float64
+	Acc float64
+
+	// Shortest duration of the reps without a time limit,
+	// or 0 if every rep had one.
+	Dur time.Duration
+}
+
+// Aggregates of the reps table that make up a `Best`, after its group.
+var bestColumns = fmt.Sprintf(
+	"max(%s), count(*), coalesce(max(%s), 0), coalesce(max(%s), 0), coalesce(min(case when %s = 0 then %s end), 0)",
+	constants.NAME, constants.WPM, constants.ACCURACY, constants.TIME_LIMIT, constants.DURATION,
+)
+
+// Scans a row of bestColumns.
+func scanBest(row interface{ Scan(...any) error }, group *string) (Best, error) {
+	var (
+		b    Best
+		name sql.NullString
+		dur  int64
+	)
+	dest := []any{&name, &b.Reps, &b.Wpm, &b.Acc, &dur}
+	if group != nil {
+		dest = append([]any{group}, dest...)
+	}
+	if err := row.Scan(dest...); err != nil {
+		return b, err
+	}
+	if group != nil {
+		b.Group = *group
+	}
+	b.Name = name.String
+	b.Dur = time.Duration(dur)
+	return b, nil
+}
+
+// Gets the best results of the reps that match the filter.
+// If no reps match, then the best has zero reps.
+func GetBest(db *sql.DB, filter RepFilter) (Best, error) {
+	query, args, err := filter.Query()
+	if err != nil {
+		return Best{}, err
+	}
+	query = fmt.Sprintf("select %s from (%s);", bestColumns, strings.TrimSuffix(query, ";"))
+	return scanBest(db.QueryRow(query, args...), nil)
+}
+
+// Gets the best results of the reps grouped by language or by
+// exercise. Exercises are told apart by their names and hashes,
+// so files with the same text aren't grouped together, and their
+// group is their hash. Reps of generated exercises, like drills,
+// are grouped by their names alone. Languages are sorted
+// alphabetically, and exercises by their names.
+func GetBests(db *sql.DB, groupBy string) ([]Best, error) {
+	var group, groups, orderBy string
+	switch groupBy {
+	case constants.LANGUAGE:
+		group = constants.LANGUAGE
+		groups = "1"
+		orderBy = constants.LANGUAGE
+	case constants.HASH:
+		group = fmt.Sprintf("case when %s in ('%s', '%s') then %s else %s end",
+			constants.NAME, constants.DRILL_NAME, constants.STDIN_NAME, constants.NAME, constants.HASH)
+		groups = fmt.Sprintf("%s, 1", constants.NAME)
+		orderBy = fmt.Sprintf("%s, 1", constants.NAME)
+	default:
+		return nil, fmt.Errorf("can't group bests by %s (use %s or %s)", groupBy, constants.LANGUAGE, constants.HASH)
+	}
+	query := fmt.Sprintf("select %s, %s from reps group by %s order by %s;",
+		group, bestColumns, groups, orderBy)
+	rows, err := db.Query(query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var bests []Best
+	for rows.Next() {
+		var group string
+		b, err := scanBest(rows, &group)
+		if err != nil {
+			return bests, err
+		}
+		bests = append(bests, b)
+	}
+	return bests, rows.Err()
+}
+
+// Gets the start time of every rep, from the earliest to the latest.
+func GetStarts(db *sql.DB) ([]time.Time, error) {
+	query := fmt.Sprintf("select %s from reps order by %s;", constants.START, constants.START)
+	rows, err := db.Query(query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var starts []time.Time
+	for rows.Next() {
+		var start int64
+		if err := rows.Scan(&start); err != nil {
+			return starts, err
+		}
+		starts = append(starts, time.UnixMilli(start))
+	}
+	return starts, rows.Err()
+}
+
+// Days in a row with at least one rep.
+type Streak struct {
+	// Midnight of the first day, in local time.
+	Start time.Time
+	Days  int
+}
+
+// Returns midnight of the last day of the streak.
+func (s Streak) End() time.Time {
+	return s.Start.AddDate(0, 0, s.Days-1)
+}
+
+// Returns the length of the streak, like `3 days`.
+func (s Streak) String() string {
+	if s.Days == 1 {
+		return "1 day"
+	}
+	return fmt.Sprintf("%d days", s.Days)
+}
+
+// Returns midnight of the day of t, in local time.
+func day(t time.Time) time.Time {
+	t = t.Local()
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.Local)
+}
+
+// Splits the days of the start times into streaks. The
+// start times must be sorted from the earliest to the latest.
+func Streaks(starts []time.Time) (ss []Streak) {
+	for _, start := range starts {
+		d := day(start)
+		if len(ss) > 0 {
+			last := &ss[len(ss)-1]
+			if !d.After(last.End()) {
+				continue
+			}
+			if d.Equal(last.End().AddDate(0, 0, 1)) {
+				last.Days++
+				continue
+			}
+		}
+		ss = append(ss, Streak{Start: d, Days: 1})
+	}
+	return
+}
+
+// Returns the streak that's still going. A streak is still going
+// if its last day is today or yesterday, since there's still time
+// to practice today. If there isn't one, then it has zero days.
+func CurrentStreak(ss []Streak, now time.Time) Streak {
+	if len(ss) == 0 {
+		return Streak{}
+	}
+	last := ss[len(ss)-1]
+	if last.End().Before(day(now).AddDate(0, 0, -1)) {
+		return Streak{}
+	}
+	return last
+}
+
+// Returns the earliest of the longest streaks.
+func LongestStreak(ss []Streak) (longest Streak) {
+	for _, s := range ss {
+		if s.Days > longest.Days {
+			longest = s
+		}
+	}
+	return
+}
+
+// Returns the messages that announce the records a rep sets.
+// The rep is compared to the reps already in the database, so
+// this is called before the rep is saved. Only the broadest new
+// best of each metric is announced, and a rep never sets a best
+// in a language or an exercise that hasn't been typed before.
+func NewRecords(db *sql.DB, rep Rep) (news []string, err error) {
+	exercise := RepFilter{Hashes: []string{rep.Hash}}
+	if rep.Generated() {
+		exercise = RepFilter{Name: rep.Name}
+	}
+	scopes := []struct {
+		label  string
+		filter RepFilter
+	}{
+		{"overall", RepFilter{}},
+		{"for " + rep.Lang, RepFilter{Langs: []string{rep.Lang}}},
+		{"for " + rep.Name, exercise},
+	}
+	if rep.Lang == "" {
+		scopes = append(scopes[:1], scopes[2:]...)
+	}
+	bests := make([]Best, len(scopes))
+	for i, scope := range scopes {
+		if bests[i], err = GetBest(db, scope.filter); err != nil {
+			return
+		}
+	}
+
+	for i, scope := range scopes {
+		if b := bests[i]; b.Reps > 0 && rep.Wpm > b.Wpm {
+			news = append(news, fmt.Sprintf("new best wpm %s: %.f (was %.f)", scope.label, rep.Wpm, b.Wpm))
+			break
+		}
+	}
+	for i, scope := range scopes {
+		if b := bests[i]; b.Reps > 0 && rep.Acc > b.Acc {
+			news = append(news, fmt.Sprintf("new best accuracy %s: %.2f%% (was %.2f%%)", scope.label, rep.Acc, b.Acc))
+			break
+		}
+	}
+	// durations are only comparable between reps of the same text
+	if b := bests[len(bests)-1]; !rep.Generated() && rep.Lim == 0 && b.Dur > 0 && rep.Dur < b.Dur {
+		news = append(news, fmt.Sprintf("new fastest rep of %s: %s (was %s)",
+			rep.Name, rep.Dur.Round(time.Millisecond), b.Dur.Round(time.Millisecond)))
+	}
+
+	starts, err := GetStarts(db)
+	if err != nil {
+		return
+	}
+	before := Streaks(starts)
+	after := Streaks(append(starts, rep.Start))
+	current := CurrentStreak(after, rep.Start)
+	if current.Days > 1 && current.Days > CurrentStreak(before, rep.Start).Days {
+		msg := fmt.Sprintf("you've practiced %s in a row", current)
+		if current.Days > LongestStreak(before).Days {
+			msg += ", your longest streak yet"
+		}
+		news = append(news, msg+"!")
+	}
+	return
+}
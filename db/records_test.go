@@ -0,0 +1,272 @@
+package db
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/NicksPatties/sweet/constants"
+)
+
+func TestBests(t *testing.T) {
+	t.Setenv("SWEET_DB_LOCATION", t.TempDir())
+
+	db, err := SweetDb()
+	if err != nil {
+		t.Fatalf("failed to initialize sweet db: %v", err)
+	}
+	defer db.Close()
+
+	start := time.Date(2024, 10, 7, 13, 0, 0, 0, time.UTC)
+	reps := []Rep{
+		{Hash: "a", Name: "a.go", Lang: "go", Wpm: 50, Acc: 99, Dur: 20 * time.Second},
+		{Hash: "a", Name: "a.go", Lang: "go", Wpm: 60, Acc: 90, Dur: 15 * time.Second},
+		{Hash: "a", Name: "a.go", Lang: "go", Wpm: 70, Acc: 95, Dur: 10 * time.Second, Lim: 10 * time.Second},
+		{Hash: "b", Name: "b.py", Lang: "py", Wpm: 80, Acc: 100, Lim: time.Minute, Dur: time.Minute},
+	}
+	for i, rep := range reps {
+		rep.Start = start.Add(time.Duration(i) * time.Minute)
+		rep.End = rep.Start.Add(rep.Dur)
+		if _, err := InsertRep(db, rep); err != nil {
+			t.Fatalf("failed to insert rep: %v", err)
+		}
+	}
+
+	t.Run("best of every rep", func(t *testing.T) {
+		got, err := GetBest(db, RepFilter{})
+		if err != nil {
+			t.Fatalf("failed to get best: %v", err)
+		}
+		want := Best{Name: "b.py", Reps: 4, Wpm: 80, Acc: 100, Dur: 15 * time.Second}
+		if got != want {
+			t.Errorf("got %+v, want %+v", got, want)
+		}
+	})
+
+	t.Run("best of no reps", func(t *testing.T) {
+		got, err := GetBest(db, RepFilter{Hashes: []string{"c"}})
+		if err != nil {
+			t.Fatalf("failed to get best: %v", err)
+		}
+		if got != (Best{}) {
+			t.Errorf("got %+v, want no best", got)
+		}
+	})
+
+	t.Run("bests by exercise", func(t *testing.T) {
+		got, err := GetBests(db, constants.HASH)
+		if err != nil {
+			t.Fatalf("failed to get bests: %v", err)
+		}
+		want := []Best{
+			{Group: "a", Name: "a.go", Reps: 3, Wpm: 70, Acc: 99, Dur: 15 * time.Second},
+			// only timed reps, so there's no fastest one
+			{Group: "b", Name: "b.py", Reps: 1, Wpm: 80, Acc: 100},
+		}
+		if len(got) != len(want) {
+			t.Fatalf("got %d bests, want %d: %+v", len(got), len(want), got)
+		}
+		for i := range want {
+			if got[i] != want[i] {
+				t.Errorf("got %+v, want %+v", got[i], want[i])
+			}
+		}
+	})
+
+	t.Run("unknown group", func(t *testing.T) {
+		if _, err := GetBests(db, constants.WPM); err == nil {
+			t.Errorf("wanted error, got nil")
+		}
+	})
+
+	t.Run("starts", func(t *testing.T) {
+		got, err := GetStarts(db)
+		if err != nil {
+			t.Fatalf("failed to get starts: %v", err)
+		}
+		if len(got) != len(reps) {
+			t.Fatalf("got %d starts, want %d", len(got), len(reps))
+		}
+		for i, s := range got {
+			if want := start.Add(time.Duration(i) * time.Minute); !s.Equal(want) {
+				t.Errorf("got start %s, want %s", s, want)
+			}
+		}
+	})
+
+	t.Run("drills are one exercise", func(t *testing.T) {
+		for i, hash := range []string{"d1", "d2"} {
+			rep := Rep{Hash: hash, Name: constants.DRILL_NAME, Wpm: float64(40 + i), Acc: 90, Dur: time.Second}
+			rep.Start = start.Add(time.Duration(10+i) * time.Minute)
+			rep.End = rep.Start.Add(rep.Dur)
+			if _, err := InsertRep(db, rep); err != nil {
+				t.Fatalf("failed to insert rep: %v", err)
+			}
+		}
+		got, err := GetBests(db, constants.HASH)
+		if err != nil {
+			t.Fatalf("failed to get bests: %v", err)
+		}
+		want := Best{Group: constants.DRILL_NAME, Name: constants.DRILL_NAME, Reps: 2, Wpm: 41, Acc: 90, Dur: time.Second}
+		if len(got) != 3 || got[2] != want {
+			t.Errorf("got %+v, want the drills last as %+v", got, want)
+		}
+	})
+
+	t.Run("files with the same text are different exercises", func(t *testing.T) {
+		rep := Rep{Hash: "a", Name: "copy.go", Lang: "go", Wpm: 30, Acc: 80, Dur: 30 * time.Second}
+		rep.Start = start.Add(20 * time.Minute)
+		rep.End = rep.Start.Add(rep.Dur)
+		if _, err := InsertRep(db, rep); err != nil {
+			t.Fatalf("failed to insert rep: %v", err)
+		}
+		got, err := GetBests(db, constants.HASH)
+		if err != nil {
+			t.Fatalf("failed to get bests: %v", err)
+		}
+		want := []Best{
+			{Group: "a", Name: "a.go", Reps: 3, Wpm: 70, Acc: 99, Dur: 15 * time.Second},
+			{Group: "b", Name: "b.py", Reps: 1, Wpm: 80, Acc: 100},
+			{Group: "a", Name: "copy.go", Reps: 1, Wpm: 30, Acc: 80, Dur: 30 * time.Second},
+			{Group: constants.DRILL_NAME, Name: constants.DRILL_NAME, Reps: 2, Wpm: 41, Acc: 90, Dur: time.Second},
+		}
+		if !slices.Equal(got, want) {
+			t.Errorf("got %+v, want %+v", got, want)
+		}
+	})
+}
+
+func date(day int, hour int) time.Time {
+	return time.Date(2024, 10, day, hour, 0, 0, 0, time.Local)
+}
+
+func TestStreaks(t *testing.T) {
+	starts := []time.Time{
+		date(1, 9), date(1, 22), date(2, 0), date(3, 23),
+		date(5, 12),
+		date(7, 8), date(8, 8),
+	}
+	got := Streaks(starts)
+	want := []Streak{
+		{Start: date(1, 0), Days: 3},
+		{Start: date(5, 0), Days: 1},
+		{Start: date(7, 0), Days: 2},
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+
+	if got := LongestStreak(got); got != want[0] {
+		t.Errorf("longest streak: got %v, want %v", got, want[0])
+	}
+
+	testCases := []struct {
+		now  time.Time
+		want int
+	}{
+		{date(8, 20), 2},
+		{date(9, 20), 2},
+		{date(10, 0), 0},
+	}
+	for _, tc := range testCases {
+		if got := CurrentStreak(got, tc.now).Days; got != tc.want {
+			t.Errorf("current streak at %s: got %d days, want %d", tc.now, got, tc.want)
+		}
+	}
+}
+
+func recordRep(hash string, lang string, start time.Time, wpm float64, acc float64, dur time.Duration) Rep {
+	return Rep{
+		Hash:  hash,
+		Name:  hash + "." + lang,
+		Lang:  lang,
+		Start: start,
+		End:   start.Add(dur),
+		Wpm:   wpm,
+		Acc:   acc,
+		Dur:   dur,
+	}
+}
+
+func TestNewRecords(t *testing.T) {
+	t.Setenv("SWEET_DB_LOCATION", t.TempDir())
+	db, err := SweetDb()
+	if err != nil {
+		t.Fatalf("failed to open database: %v", err)
+	}
+	defer db.Close()
+
+	for _, rep := range []Rep{
+		recordRep("a", "go", date(1, 9), 80, 100, 10*time.Second),
+		recordRep("b", "go", date(2, 9), 60, 95, 20*time.Second),
+		recordRep("c", "py", date(3, 9), 50, 90, 30*time.Second),
+	} {
+		if _, err := InsertRep(db, rep); err != nil {
+			t.Fatalf("failed to insert rep: %v", err)
+		}
+	}
+
+	testCases := []struct {
+		name string
+		rep  Rep
+		want []string
+	}{
+		{
+			name: "nothing new",
+			rep:  recordRep("b", "go", date(3, 12), 55, 90, 25*time.Second),
+			want: nil,
+		},
+		{
+			name: "best of an exercise and a language",
+			rep:  recordRep("b", "go", date(3, 12), 70, 99, 15*time.Second),
+			want: []string{
+				"new best wpm for b.go: 70 (was 60)",
+				"new best accuracy for b.go: 99.00% (was 95.00%)",
+				"new fastest rep of b.go: 15s (was 20s)",
+			},
+		},
+		{
+			name: "only the broadest best is announced",
+			rep:  recordRep("c", "py", date(3, 12), 90, 95, 40*time.Second),
+			want: []string{
+				"new best wpm overall: 90 (was 80)",
+				"new best accuracy for py: 95.00% (was 90.00%)",
+			},
+		},
+		{
+			name: "first rep of an exercise",
+			rep:  recordRep("d", "rs", date(3, 12), 10, 50, time.Second),
+			want: nil,
+		},
+		{
+			name: "extended streak",
+			rep:  recordRep("d", "rs", date(4, 12), 10, 50, time.Second),
+			want: []string{"you've practiced 4 days in a row, your longest streak yet!"},
+		},
+	}
+
+	for _, tc := range testCases {
+		got, err := NewRecords(db, tc.rep)
+		if err != nil {
+			t.Fatalf("%s: failed to get records: %v", tc.name, err)
+		}
+		if !slices.Equal(got, tc.want) {
+			t.Errorf("%s:\n  got:  %q\n  want: %q", tc.name, got, tc.want)
+		}
+	}
+	// drills have new text every rep, so they're compared by name,
+	// and their durations aren't compared at all
+	drill := Rep{Hash: "d1", Name: constants.DRILL_NAME, Start: date(3, 10), Wpm: 20, Acc: 50, Dur: 20 * time.Second}
+	drill.End = drill.Start.Add(drill.Dur)
+	if _, err := InsertRep(db, drill); err != nil {
+		t.Fatalf("failed to insert rep: %v", err)
+	}
+	drill = Rep{Hash: "d2", Name: constants.DRILL_NAME, Start: date(3, 12), Wpm: 30, Acc: 50, Dur: 10 * time.Second}
+	got, err := NewRecords(db, drill)
+	if err != nil {
+		t.Fatalf("failed to get drill records: %v", err)
+	}
+	if want := []string{"new best wpm for drill: 30 (was 20)"}; !slices.Equal(got, want) {
+		t.Errorf("drill:\n  got:  %q\n  want: %q", got, want)
+	}
+}
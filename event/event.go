@@ -28,11 +28,13 @@ type Event struct {
 	I int
 }
 
+// The layout of an event's timestamp. Timestamps are written in
+// local time, so they're parsed in local time, too.
 const EventTsLayout = "2006-01-02 15:04:05.000"
 
 // Converts an event to a string.
 func (e Event) String() string {
-	time := e.Ts.Format(EventTsLayout)
+	time := e.Ts.Local().Format(EventTsLayout)
 	return fmt.Sprintf("%s\t%d\t%s\t%s", time, e.I, e.Typed, e.Expected)
 }
 
@@ -48,7 +50,7 @@ func (a Event) Matches(b Event) bool {
 // Converts an event string to an event struct.
 func ParseEvent(line string) (e Event) {
 	s := strings.Split(line, "\t")
-	e.Ts, _ = time.Parse(EventTsLayout, s[0])
+	e.Ts, _ = time.ParseInLocation(EventTsLayout, s[0], time.Local)
 	e.I, _ = strconv.Atoi(s[1])
 	e.Typed = s[2]
 	if len(s) > 3 {
@@ -115,3 +117,19 @@ func RuneToEventExpected(r rune) string {
 		return string(r)
 	}
 }
+
+// Converts the typed key of an event back to the rune that
+// was typed. Backspaces and empty keys have no rune, so they
+// return 0.
+func EventTypedToRune(typed string) rune {
+	switch typed {
+	case "enter":
+		return '\n'
+	case "space":
+		return ' '
+	case "backspace", "":
+		return 0
+	default:
+		return []rune(typed)[0]
+	}
+}
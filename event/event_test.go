@@ -7,7 +7,7 @@ import (
 )
 
 func getEventTs(s string) (t time.Time) {
-	t, _ = time.Parse(EventTsLayout, s)
+	t, _ = time.ParseInLocation(EventTsLayout, s, time.Local)
 	return
 }
 
@@ -116,3 +116,23 @@ func TestParseEvents(t *testing.T) {
 		}
 	}
 }
+
+func TestEventTypedToRune(t *testing.T) {
+	testCases := []struct {
+		typed string
+		want  rune
+	}{
+		{typed: "a", want: 'a'},
+		{typed: "enter", want: '\n'},
+		{typed: "space", want: ' '},
+		{typed: "backspace", want: 0},
+		{typed: "", want: 0},
+	}
+
+	for _, tc := range testCases {
+		got := EventTypedToRune(tc.typed)
+		if got != tc.want {
+			t.Errorf("%q: got %q, want %q", tc.typed, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,84 @@
+package event
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// A period of time during an exercise where the user wasn't typing,
+// either because they paused the exercise, or because they were idle.
+//
+// Pauses are left out of the exercise's duration and wpm, so taking a
+// break doesn't ruin a rep.
+type Pause struct {
+	// The moment the pause started.
+	Start time.Time
+
+	// The moment the exercise resumed. Zero if the exercise
+	// is still paused.
+	End time.Time
+}
+
+// Converts a pause to a string. The end is left
+// empty if the exercise is still paused.
+func (p Pause) String() string {
+	end := ""
+	if !p.End.IsZero() {
+		end = p.End.Local().Format(EventTsLayout)
+	}
+	return fmt.Sprintf("%s\t%s", p.Start.Local().Format(EventTsLayout), end)
+}
+
+// Converts a pause string to a pause struct.
+func ParsePause(line string) (p Pause) {
+	s := strings.Split(line, "\t")
+	p.Start, _ = time.ParseInLocation(EventTsLayout, s[0], time.Local)
+	if len(s) > 1 {
+		p.End, _ = time.ParseInLocation(EventTsLayout, s[1], time.Local)
+	}
+	return
+}
+
+type Pauses []Pause
+
+// Same as above, but for a multi-line list of pauses.
+func ParsePauses(list string) (pauses Pauses) {
+	for _, line := range strings.Split(list, "\n") {
+		if line != "" {
+			pauses = append(pauses, ParsePause(line))
+		}
+	}
+	return
+}
+
+// Returns the total time spent paused before t. If a pause is
+// still going on at t, then it counts up until t.
+func (pauses Pauses) Before(t time.Time) (d time.Duration) {
+	for _, p := range pauses {
+		if !p.Start.Before(t) {
+			continue
+		}
+		end := p.End
+		if end.IsZero() || end.After(t) {
+			end = t
+		}
+		d += end.Sub(p.Start)
+	}
+	return
+}
+
+// Returns a copy of the events with each of their timestamps moved
+// back by the time spent paused before them, as if the pauses never
+// happened. Used to calculate the duration and wpm of a rep.
+func RemovePauses(events []Event, pauses Pauses) []Event {
+	if len(pauses) == 0 {
+		return events
+	}
+	active := make([]Event, len(events))
+	for i, e := range events {
+		e.Ts = e.Ts.Add(-pauses.Before(e.Ts))
+		active[i] = e
+	}
+	return active
+}
@@ -0,0 +1,103 @@
+package event
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParsePauses(t *testing.T) {
+	in := "2024-10-07 13:46:47.679\t2024-10-07 13:47:00.000\n" +
+		"2024-10-07 13:48:00.000\t2024-10-07 13:48:30.500\n"
+	want := Pauses{
+		{Start: getEventTs("2024-10-07 13:46:47.679"), End: getEventTs("2024-10-07 13:47:00.000")},
+		{Start: getEventTs("2024-10-07 13:48:00.000"), End: getEventTs("2024-10-07 13:48:30.500")},
+	}
+	got := ParsePauses(in)
+	if len(got) != len(want) {
+		t.Fatalf("got %d pauses, want %d", len(got), len(want))
+	}
+	for i := range want {
+		if !got[i].Start.Equal(want[i].Start) || !got[i].End.Equal(want[i].End) {
+			t.Errorf("pause %d: got %s, want %s", i, got[i], want[i])
+		}
+		if ParsePause(want[i].String()) != want[i] {
+			t.Errorf("pause %d: %q didn't parse back to itself", i, want[i].String())
+		}
+	}
+
+	unfinished := Pause{Start: want[0].Start}
+	if got := ParsePause(unfinished.String()); !got.End.IsZero() || !got.Start.Equal(unfinished.Start) {
+		t.Errorf("unfinished pause %q parsed to %s", unfinished.String(), got)
+	}
+}
+
+func TestPausesBefore(t *testing.T) {
+	start := getEventTs("2024-10-07 13:00:00.000")
+	pauses := Pauses{
+		{Start: start.Add(10 * time.Second), End: start.Add(20 * time.Second)},
+		{Start: start.Add(30 * time.Second)}, // still paused
+	}
+
+	testCases := []struct {
+		name string
+		t    time.Time
+		want time.Duration
+	}{
+		{
+			name: "before any pauses",
+			t:    start.Add(5 * time.Second),
+			want: 0,
+		},
+		{
+			name: "during a pause",
+			t:    start.Add(15 * time.Second),
+			want: 5 * time.Second,
+		},
+		{
+			name: "after a pause",
+			t:    start.Add(25 * time.Second),
+			want: 10 * time.Second,
+		},
+		{
+			name: "during a pause that hasn't ended",
+			t:    start.Add(40 * time.Second),
+			want: 20 * time.Second,
+		},
+	}
+
+	for _, tc := range testCases {
+		got := pauses.Before(tc.t)
+		if got != tc.want {
+			t.Errorf("%s: got %s, want %s", tc.name, got, tc.want)
+		}
+	}
+}
+
+func TestRemovePauses(t *testing.T) {
+	events := ParseEvents(
+		"2024-10-07 13:00:00.000\t0\ta\ta\n" +
+			"2024-10-07 13:00:01.000\t1\ts\ts\n" +
+			"2024-10-07 13:05:01.500\t2\td\td\n" +
+			"2024-10-07 13:05:02.000\t3\tf\tf\n",
+	)
+	pauses := Pauses{{
+		Start: getEventTs("2024-10-07 13:00:01.000"),
+		End:   getEventTs("2024-10-07 13:05:01.000"),
+	}}
+	want := []time.Time{
+		getEventTs("2024-10-07 13:00:00.000"),
+		getEventTs("2024-10-07 13:00:01.000"),
+		getEventTs("2024-10-07 13:00:01.500"),
+		getEventTs("2024-10-07 13:00:02.000"),
+	}
+
+	got := RemovePauses(events, pauses)
+	for i, ts := range want {
+		if !got[i].Ts.Equal(ts) {
+			t.Errorf("event %d: got %s, want %s", i, got[i].Ts.Format(EventTsLayout), ts.Format(EventTsLayout))
+		}
+	}
+	if !events[2].Ts.Equal(getEventTs("2024-10-07 13:05:01.500")) {
+		t.Errorf("the original events shouldn't be changed")
+	}
+}
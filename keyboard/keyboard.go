@@ -0,0 +1,314 @@
+// Keyboard layouts, and the fingers used to type each of their keys.
+package keyboard
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	lg "github.com/charmbracelet/lipgloss"
+)
+
+type Finger uint
+
+const (
+	LeftPinky Finger = iota
+	LeftRing
+	LeftMiddle
+	LeftIndex
+	LeftThumb
+	RightThumb
+	RightIndex
+	RightMiddle
+	RightRing
+	RightPinky
+)
+
+var fingerNames = []string{
+	"left pinky", "left ring", "left middle", "left index", "left thumb",
+	"right thumb", "right index", "right middle", "right ring", "right pinky",
+}
+
+// Returns the finger's name, like "left pinky".
+func (f Finger) String() string {
+	if int(f) >= len(fingerNames) {
+		return fmt.Sprintf("finger %d", f)
+	}
+	return fingerNames[f]
+}
+
+// A keyboard layout. Used to show the user which keys and
+// fingers to use to type the next character of an exercise.
+type Layout struct {
+	Name string
+
+	keys          [][]string
+	modifiedKeys  [][]string
+	margins       []int
+	fingersMargin int
+
+	// Rune to fingers
+	rtfs map[rune][]Finger
+}
+
+// The fingers used to type the keys in each row of a
+// standard keyboard, one for each key in the layout's rows.
+// Every layout shares the same physical keys, so they also
+// share the fingers used to press them.
+var standardFingers = [][]Finger{
+	{
+		LeftPinky, LeftPinky, LeftRing, LeftMiddle, LeftIndex, LeftIndex,
+		RightIndex, RightIndex, RightMiddle, RightRing, RightPinky, RightPinky, RightPinky,
+	},
+	{
+		LeftPinky, LeftRing, LeftMiddle, LeftIndex, LeftIndex,
+		RightIndex, RightIndex, RightMiddle, RightRing, RightPinky, RightPinky, RightPinky, RightPinky,
+	},
+	{
+		LeftPinky, LeftRing, LeftMiddle, LeftIndex, LeftIndex,
+		RightIndex, RightIndex, RightMiddle, RightRing, RightPinky, RightPinky, RightPinky,
+	},
+	{
+		LeftPinky, LeftPinky, LeftPinky, LeftRing, LeftMiddle, LeftIndex, LeftIndex,
+		RightIndex, RightIndex, RightMiddle, RightRing, RightPinky,
+	},
+	{
+		LeftThumb,
+	},
+}
+
+// Creates a layout with the rows of a standard keyboard.
+// The first row is the number row, and the last row is the
+// space bar. Both sets of rows must match the shape of
+// `standardFingers`.
+func newLayout(name string, keys [][]string, modifiedKeys [][]string) Layout {
+	l := Layout{
+		Name:          name,
+		keys:          keys,
+		modifiedKeys:  modifiedKeys,
+		margins:       []int{3, 4, 5, 0, 8},
+		fingersMargin: 5,
+		rtfs:          map[rune][]Finger{},
+	}
+	for ri, row := range keys {
+		for ki, key := range row {
+			if r, ok := keyRune(key); ok {
+				l.rtfs[r] = []Finger{standardFingers[ri][ki]}
+			}
+		}
+	}
+	// Modified keys also need the pinky on the opposite
+	// hand to hold shift.
+	for ri, row := range modifiedKeys {
+		for ki, key := range row {
+			r, ok := keyRune(key)
+			if _, found := l.rtfs[r]; !ok || found {
+				continue
+			}
+			finger := standardFingers[ri][ki]
+			shift := RightPinky
+			if finger > LeftThumb {
+				shift = LeftPinky
+			}
+			l.rtfs[r] = []Finger{finger, shift}
+		}
+	}
+	return l
+}
+
+// Returns the rune that's typed by a key in a layout's row.
+// Keys that don't type anything, like shift, return false.
+func keyRune(key string) (rune, bool) {
+	switch key {
+	case "↲":
+		return '\n', true
+	case "space":
+		return ' ', true
+	case "shift", " ":
+		return 0, false
+	}
+	if utf8.RuneCountInString(key) != 1 {
+		return 0, false
+	}
+	r, _ := utf8.DecodeRuneInString(key)
+	return r, true
+}
+
+// Returns the fingers used to type the rune. If the rune
+// isn't on the layout, then nil is returned.
+func (l Layout) Fingers(r rune) []Finger {
+	return l.rtfs[r]
+}
+
+func (l Layout) FindKeyCombo(char string) (combo []string) {
+
+	if char == "\n" {
+		return []string{"↲"}
+	}
+
+	if char == " " {
+		return []string{"space"}
+	}
+
+	for _, row := range l.keys {
+		for _, key := range row {
+			if key == char {
+				return []string{key}
+			}
+		}
+	}
+
+	for i, row := range l.modifiedKeys {
+		for j, key := range row {
+			if key == char {
+				return []string{"shift", l.keys[i][j]}
+			}
+		}
+	}
+
+	return
+}
+
+// Renders the keymap. Uses a key that needs to be
+// rendered as input, and the style to highlight it with.
+func (l Layout) Render(char string, hk lg.Style) string {
+	combo := l.FindKeyCombo(char)
+	var currKey string
+	if len(combo) == 0 {
+		currKey = ""
+	} else {
+		currKey = combo[len(combo)-1]
+	}
+	isShift := len(combo) > 1
+	return l.RenderKeys(func(key string) lg.Style {
+		if key == currKey || key == "shift" && isShift {
+			return hk
+		}
+		return lg.NewStyle()
+	})
+}
+
+// Renders the keymap with each key in its own style.
+// The style function is called with every key's label,
+// like "a", "shift", or "space".
+func (l Layout) RenderKeys(style func(key string) lg.Style) (km string) {
+	rows := len(l.keys)
+	for ri, row := range l.keys {
+		km += strings.Repeat(" ", l.margins[ri])
+		for _, key := range row {
+			if key == " " {
+				km += key
+			} else {
+				km += style(key).Render(key)
+			}
+		}
+		if ri != rows-1 {
+			km += "\n"
+		}
+	}
+	return
+}
+
+// Returns the key that types the character, or an empty
+// string if the character isn't on the layout. Characters
+// that need shift return the key that's pressed with it.
+func (l Layout) Key(char string) string {
+	combo := l.FindKeyCombo(char)
+	if len(combo) == 0 {
+		return ""
+	}
+	return combo[len(combo)-1]
+}
+
+// Returns a view of the fingers used to type the character,
+// aligned with the layout's keymap. The fingers are highlighted
+// with the same style as the keys.
+func (l Layout) RenderFingers(fIcon rune, currChar rune, hk lg.Style) string {
+	return renderFingers(l.fingersMargin, fIcon, l.Fingers(currChar), hk)
+}
+
+// Returns a view of the fingers for the keymap.
+// margin is the spacing on the left to push
+// fi is finger icon (which will actually be determined by)
+// activeFingers are the fingers used to type the current character
+// hk is the style of the active fingers
+func renderFingers(margin int, fIcon rune, activeFingers []Finger, hk lg.Style) (view string) {
+
+	// fingers view
+	f := [][]rune{
+		{rune(LeftPinky), fIcon},
+		{rune(LeftRing), fIcon, fIcon},
+		{rune(LeftMiddle), fIcon, fIcon},
+		{rune(LeftIndex), fIcon},
+		{rune(LeftThumb)},
+		{rune(RightThumb)},
+		{rune(RightIndex), fIcon},
+		{rune(RightMiddle), fIcon, fIcon},
+		{rune(RightRing), fIcon, fIcon},
+		{rune(RightPinky), fIcon},
+	}
+
+	for row := len(f[LeftRing]) - 1; row >= 0; row-- {
+		for space := 0; space < margin; space++ {
+			view += " "
+		}
+		// curr fingers
+		for cf := 0; cf < len(f); cf++ {
+			// if this is a finger spot, then I should print the character
+			// in the finger view location
+			if isFingerSpot := row < len(f[cf]); isFingerSpot {
+				style := lg.NewStyle()
+
+				isActive := false
+				for _, finger := range activeFingers {
+					if finger == Finger(cf) {
+						isActive = true
+					}
+				}
+				if isActive {
+					style = hk
+				}
+				if row == 0 {
+					view += style.Render(strconv.Itoa(cf))
+				} else {
+					view += style.Render(string(fIcon))
+				}
+			} else {
+				view += " "
+			}
+			// space in between the hands
+			if cf == 4 {
+				view += " "
+			}
+		}
+		// not last row
+		if row != 0 {
+			view += "\n"
+		}
+	}
+	return
+}
+
+// The layout that's used if the user doesn't choose one.
+const DefaultLayout = "qwerty"
+
+// Gets a layout by its name.
+func Get(name string) (Layout, error) {
+	l, ok := layouts[strings.ToLower(name)]
+	if !ok {
+		return Layout{}, fmt.Errorf("unknown keyboard layout %q, must be one of: %s",
+			name, strings.Join(Names(), ", "))
+	}
+	return l, nil
+}
+
+// Returns the names of every layout, sorted alphabetically.
+func Names() (names []string) {
+	for name := range layouts {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return
+}
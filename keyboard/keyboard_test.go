@@ -0,0 +1,199 @@
+package keyboard
+
+import (
+	"fmt"
+	"testing"
+
+	lg "github.com/charmbracelet/lipgloss"
+)
+
+func TestFingerView(t *testing.T) {
+
+	testCases := []struct {
+		name   string
+		icon   rune
+		char   rune
+		margin int
+		want   string
+	}{
+		{
+			name:   "Stars for fingers",
+			icon:   '*',
+			margin: 0,
+			want: "" +
+				" **     ** \n" +
+				"****   ****\n" +
+				"01234 56789",
+		},
+		{
+			name:   "Stars for fingers, margins",
+			icon:   '*',
+			margin: 2,
+			want: "" +
+				"   **     ** \n" +
+				"  ****   ****\n" +
+				"  01234 56789",
+		},
+	}
+	for _, tc := range testCases {
+		// just passing a character because
+		got := renderFingers(tc.margin, tc.icon, qwerty.Fingers('a'), lg.NewStyle().Reverse(true))
+		if got != tc.want {
+			t.Errorf("%s:\ngot:\n%s\n\nwant:\n%s", tc.name, got, tc.want)
+		}
+	}
+}
+
+func TestFindKeyCombo(t *testing.T) {
+	testCases := []struct {
+		name string
+		char string
+		want []string
+	}{
+		{
+			name: "on unmodified keys",
+			char: "a",
+			want: []string{"a"},
+		},
+		{
+			name: "on modified keys",
+			char: "W",
+			want: []string{"shift", "w"},
+		},
+		{
+			name: "newline",
+			char: "\n",
+			want: []string{"↲"},
+		},
+		{
+			name: "space",
+			char: " ",
+			want: []string{"space"},
+		},
+		{
+			name: "no character",
+			char: "",
+			want: []string{},
+		},
+	}
+
+	for _, tc := range testCases {
+		g := qwerty.FindKeyCombo(tc.char)
+		for i, got := range g {
+			want := tc.want[i]
+			if got != want {
+				t.Errorf("%s:\n\tgot %s\n\twant %s\n", tc.name, g, tc.want)
+			}
+		}
+	}
+
+}
+
+func TestFingers(t *testing.T) {
+	testCases := []struct {
+		layout Layout
+		char   rune
+		want   []Finger
+	}{
+		{layout: qwerty, char: 'a', want: []Finger{LeftPinky}},
+		{layout: qwerty, char: 'n', want: []Finger{RightIndex}},
+		{layout: qwerty, char: 'A', want: []Finger{LeftPinky, RightPinky}},
+		{layout: qwerty, char: ')', want: []Finger{RightPinky, LeftPinky}},
+		{layout: qwerty, char: ' ', want: []Finger{LeftThumb}},
+		{layout: qwerty, char: '\n', want: []Finger{RightPinky}},
+		{layout: dvorak, char: 'o', want: []Finger{LeftRing}},
+		{layout: dvorak, char: 'Z', want: []Finger{RightPinky, LeftPinky}},
+		{layout: dvorak, char: '-', want: []Finger{RightPinky}},
+		{layout: colemak, char: 'n', want: []Finger{RightIndex}},
+		{layout: colemak, char: 'T', want: []Finger{LeftIndex, RightPinky}},
+		{layout: workman, char: 'h', want: []Finger{LeftMiddle}},
+		{layout: workman, char: 'o', want: []Finger{RightRing}},
+		{layout: workman, char: 'λ', want: nil},
+	}
+
+	for _, tc := range testCases {
+		got := tc.layout.Fingers(tc.char)
+		if fmt.Sprint(got) != fmt.Sprint(tc.want) {
+			t.Errorf("%s %q: got %v, want %v", tc.layout.Name, tc.char, got, tc.want)
+		}
+	}
+}
+
+func TestLayouts(t *testing.T) {
+	for _, name := range Names() {
+		l, err := Get(name)
+		if err != nil {
+			t.Fatalf("%s: %s", name, err)
+		}
+		if len(l.keys) != len(standardFingers) || len(l.modifiedKeys) != len(standardFingers) {
+			t.Fatalf("%s: has the wrong number of rows", name)
+		}
+		for ri := range standardFingers {
+			if len(l.keys[ri]) != len(standardFingers[ri]) || len(l.modifiedKeys[ri]) != len(standardFingers[ri]) {
+				t.Errorf("%s: row %d has the wrong number of keys", name, ri)
+			}
+		}
+		// every layout should be able to type the same characters
+		for r := range qwerty.rtfs {
+			if len(l.Fingers(r)) == 0 {
+				t.Errorf("%s: can't type %q", name, r)
+			}
+		}
+	}
+
+	if _, err := Get("Dvorak"); err != nil {
+		t.Errorf("layout names shouldn't be case sensitive: %s", err)
+	}
+	if _, err := Get("azerty"); err == nil {
+		t.Errorf("wanted an error for an unknown layout")
+	}
+}
+
+func TestKey(t *testing.T) {
+	testCases := []struct {
+		char string
+		want string
+	}{
+		{char: "a", want: "a"},
+		{char: "W", want: "w"},
+		{char: ")", want: "0"},
+		{char: "\n", want: "↲"},
+		{char: "λ", want: ""},
+		{char: "", want: ""},
+	}
+
+	for _, tc := range testCases {
+		if got := qwerty.Key(tc.char); got != tc.want {
+			t.Errorf("%q: got %q, want %q", tc.char, got, tc.want)
+		}
+	}
+}
+
+func TestRenderKeys(t *testing.T) {
+	styled := []string{}
+	got := qwerty.RenderKeys(func(key string) lg.Style {
+		styled = append(styled, key)
+		return lg.NewStyle()
+	})
+	if got != qwerty.Render("", lg.NewStyle()) {
+		t.Errorf("unstyled keys differ from the keymap:\n%s", got)
+	}
+	for _, key := range styled {
+		if key == " " {
+			t.Errorf("padding between keys shouldn't be styled")
+			break
+		}
+	}
+	if len(styled) == 0 {
+		t.Errorf("no keys were styled")
+	}
+}
+
+func TestFingerString(t *testing.T) {
+	if got := LeftPinky.String(); got != "left pinky" {
+		t.Errorf("got %q, want %q", got, "left pinky")
+	}
+	if got := RightPinky.String(); got != "right pinky" {
+		t.Errorf("got %q, want %q", got, "right pinky")
+	}
+}
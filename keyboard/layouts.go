@@ -0,0 +1,159 @@
+package keyboard
+
+// Every layout that can be selected, by name.
+var layouts = map[string]Layout{
+	qwerty.Name:  qwerty,
+	dvorak.Name:  dvorak,
+	colemak.Name: colemak,
+	workman.Name: workman,
+}
+
+var qwerty = newLayout("qwerty",
+	[][]string{
+		{
+			"`", "1", "2", "3", "4", "5", "6", "7", "8", "9", "0", "-", "=",
+		},
+		{
+			"q", "w", "e", "r", "t", "y", "u", "i", "o", "p", "[", "]", "\\",
+		},
+		{
+			"a", "s", "d", "f", "g", "h", "j", "k", "l", ";", "'", "↲",
+		},
+		// Spaces in this array are purely cosmetic. They're used to
+		// add padding between "shift" and "z" in the keymap.
+		{
+			"shift", " ", "z", "x", "c", "v", "b", "n", "m", ",", ".", "/",
+		},
+		{
+			"space",
+		},
+	},
+	[][]string{
+		{
+			"~", "!", "@", "#", "$", "%", "^", "&", "*", "(", ")", "_", "+",
+		},
+		{
+			"Q", "W", "E", "R", "T", "Y", "U", "I", "O", "P", "{", "}", "|",
+		},
+		{
+			"A", "S", "D", "F", "G", "H", "J", "K", "L", ":", "\"", "↲",
+		},
+		{
+			"shift", " ", "Z", "X", "C", "V", "B", "N", "M", "<", ">", "?",
+		},
+		{
+			"space",
+		},
+	},
+)
+
+var dvorak = newLayout("dvorak",
+	[][]string{
+		{
+			"`", "1", "2", "3", "4", "5", "6", "7", "8", "9", "0", "[", "]",
+		},
+		{
+			"'", ",", ".", "p", "y", "f", "g", "c", "r", "l", "/", "=", "\\",
+		},
+		{
+			"a", "o", "e", "u", "i", "d", "h", "t", "n", "s", "-", "↲",
+		},
+		{
+			"shift", " ", ";", "q", "j", "k", "x", "b", "m", "w", "v", "z",
+		},
+		{
+			"space",
+		},
+	},
+	[][]string{
+		{
+			"~", "!", "@", "#", "$", "%", "^", "&", "*", "(", ")", "{", "}",
+		},
+		{
+			"\"", "<", ">", "P", "Y", "F", "G", "C", "R", "L", "?", "+", "|",
+		},
+		{
+			"A", "O", "E", "U", "I", "D", "H", "T", "N", "S", "_", "↲",
+		},
+		{
+			"shift", " ", ":", "Q", "J", "K", "X", "B", "M", "W", "V", "Z",
+		},
+		{
+			"space",
+		},
+	},
+)
+
+var colemak = newLayout("colemak",
+	[][]string{
+		{
+			"`", "1", "2", "3", "4", "5", "6", "7", "8", "9", "0", "-", "=",
+		},
+		{
+			"q", "w", "f", "p", "g", "j", "l", "u", "y", ";", "[", "]", "\\",
+		},
+		{
+			"a", "r", "s", "t", "d", "h", "n", "e", "i", "o", "'", "↲",
+		},
+		{
+			"shift", " ", "z", "x", "c", "v", "b", "k", "m", ",", ".", "/",
+		},
+		{
+			"space",
+		},
+	},
+	[][]string{
+		{
+			"~", "!", "@", "#", "$", "%", "^", "&", "*", "(", ")", "_", "+",
+		},
+		{
+			"Q", "W", "F", "P", "G", "J", "L", "U", "Y", ":", "{", "}", "|",
+		},
+		{
+			"A", "R", "S", "T", "D", "H", "N", "E", "I", "O", "\"", "↲",
+		},
+		{
+			"shift", " ", "Z", "X", "C", "V", "B", "K", "M", "<", ">", "?",
+		},
+		{
+			"space",
+		},
+	},
+)
+
+var workman = newLayout("workman",
+	[][]string{
+		{
+			"`", "1", "2", "3", "4", "5", "6", "7", "8", "9", "0", "-", "=",
+		},
+		{
+			"q", "d", "r", "w", "b", "j", "f", "u", "p", ";", "[", "]", "\\",
+		},
+		{
+			"a", "s", "h", "t", "g", "y", "n", "e", "o", "i", "'", "↲",
+		},
+		{
+			"shift", " ", "z", "x", "m", "c", "v", "k", "l", ",", ".", "/",
+		},
+		{
+			"space",
+		},
+	},
+	[][]string{
+		{
+			"~", "!", "@", "#", "$", "%", "^", "&", "*", "(", ")", "_", "+",
+		},
+		{
+			"Q", "D", "R", "W", "B", "J", "F", "U", "P", ":", "{", "}", "|",
+		},
+		{
+			"A", "S", "H", "T", "G", "Y", "N", "E", "O", "I", "\"", "↲",
+		},
+		{
+			"shift", " ", "Z", "X", "M", "C", "V", "K", "L", "<", ">", "?",
+		},
+		{
+			"space",
+		},
+	},
+)